@@ -0,0 +1,485 @@
+// logq 查询 logger 写出的日志文件，支持滚动产生的历史文件与 .gz 压缩文件，
+// 可识别默认文本格式与 JSON Lines。
+//
+// 用法：
+//
+//	logq [flags] [file|glob ...]
+//
+// 不指定文件时读取标准输入。多个文件按修改时间从旧到新读取。
+//
+// 示例：
+//
+//	logq -backups -level warn -since 2h ./logs/app.log       # 当前文件及其历史文件中 2 小时内的 WARN 及以上
+//	logq -trace 4bf92f3577b34da6a3ce929d0e0e4736 './logs/*.gz'
+//	logq -grep 'timeout|refused' -stats ./logs/app-2026-10-*.log.gz
+//	logq -f -level error ./logs/app.log                      # 类似 tail -f
+//	logq -key $LOG_ENCRYPT_KEY -backups ./logs/audit.log      # 解密 WithEncryption 写出的文件
+//
+// 加密文件（含滚动与 gzip 后的）按内容自动识别，密钥取自 -key 或环境变量 LOG_ENCRYPT_KEY。
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"utils/crpyto/aes"
+	"utils/logger"
+)
+
+type filter struct {
+	minLevel logger.Level
+	hasLevel bool
+	since    time.Time
+	until    time.Time
+	traceID  string
+	re       *regexp.Regexp
+}
+
+// match 按条件过滤记录；有级别、时间或 traceID 条件时，无法识别格式的行不输出
+func (f *filter) match(r *record) bool {
+	if !r.Parsed && (f.hasLevel || !f.since.IsZero() || !f.until.IsZero() || f.traceID != "") {
+		return false
+	}
+	if f.hasLevel && r.Level < f.minLevel {
+		return false
+	}
+	if !f.since.IsZero() && r.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !r.Time.Before(f.until) {
+		return false
+	}
+	if f.traceID != "" && r.TraceID != f.traceID {
+		return false
+	}
+	if f.re != nil && !f.re.MatchString(r.Raw) {
+		return false
+	}
+	return true
+}
+
+func main() {
+	var (
+		level   = flag.String("level", "", "最低级别：debug/info/warn/error/fatal")
+		since   = flag.String("since", "", "开始时间，如 \"2026-10-16 08:00\"、RFC3339，或相对时长如 30m、2h")
+		until   = flag.String("until", "", "结束时间（不含），格式同 -since")
+		trace   = flag.String("trace", "", "只输出该 traceID 的日志")
+		grep    = flag.String("grep", "", "正则表达式，匹配整条日志（含字段与调用栈）")
+		follow  = flag.Bool("f", false, "读完后持续跟踪最后一个文件的新增内容，文件被滚动后自动重新打开")
+		stats   = flag.Bool("stats", false, "只输出按级别与按分钟的统计")
+		backups = flag.Bool("backups", false, "同时读取每个文件的滚动历史文件（含 .gz）")
+		keyHex  = flag.String("key", os.Getenv("LOG_ENCRYPT_KEY"), "解密用的十六进制密钥，默认取环境变量 LOG_ENCRYPT_KEY")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: logq [flags] [file|glob ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	f, err := buildFilter(*level, *since, *until, *trace, *grep)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if *follow && *stats {
+		fatalf("-f 与 -stats 不能同时使用")
+	}
+	key, err := parseKey(*keyHex)
+	if err != nil {
+		fatalf("-key: %v", err)
+	}
+	files, err := expandFiles(flag.Args(), *backups)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if *follow && (len(files) == 0 || strings.HasSuffix(files[len(files)-1], ".gz")) {
+		fatalf("-f 需要指定一个未压缩的日志文件")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	st := newStats()
+	c := &collector{emit: func(r *record) {
+		if !f.match(r) {
+			return
+		}
+		if *stats {
+			st.add(r)
+			return
+		}
+		out.WriteString(r.Raw)
+		out.WriteByte('\n')
+	}}
+
+	if len(files) == 0 {
+		if err := scanReader("stdin", os.Stdin, key, c); err != nil {
+			fatalf("read stdin: %v", err)
+		}
+	}
+	for i, name := range files {
+		if *follow && i == len(files)-1 {
+			out.Flush()
+			c.emit = flushAfter(c.emit, out)
+			if err := followFile(ctx, name, c); err != nil {
+				fatalf("%s: %v", name, err)
+			}
+			break
+		}
+		if err := scanFile(name, key, c); err != nil {
+			fmt.Fprintf(os.Stderr, "logq: %s: %v\n", name, err)
+		}
+	}
+	c.flush()
+
+	if *stats {
+		st.print(out)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "logq: "+format+"\n", args...)
+	os.Exit(2)
+}
+
+func buildFilter(level, since, until, trace, grep string) (*filter, error) {
+	f := &filter{traceID: trace}
+	if level != "" {
+		l, err := logger.ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		f.minLevel, f.hasLevel = l, true
+	}
+	var err error
+	if f.since, err = parseTime(since); err != nil {
+		return nil, fmt.Errorf("-since: %w", err)
+	}
+	if f.until, err = parseTime(until); err != nil {
+		return nil, fmt.Errorf("-until: %w", err)
+	}
+	if grep != "" {
+		if f.re, err = regexp.Compile(grep); err != nil {
+			return nil, fmt.Errorf("-grep: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// parseTime 解析绝对时间（本地时区）或相对于现在的时长
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间 %q", s)
+}
+
+// =============================
+// 文件
+// =============================
+
+// expandFiles 展开通配符并按修改时间从旧到新排序，withBackups 时加入各文件的滚动历史文件
+func expandFiles(args []string, withBackups bool) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	for _, arg := range args {
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(arg); err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%s: 没有匹配的文件", arg)
+			}
+		}
+		for _, m := range matches {
+			if withBackups {
+				for _, b := range backupsOf(m) {
+					add(b)
+				}
+			}
+			add(m)
+		}
+	}
+
+	mtime := make(map[string]time.Time, len(files))
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		mtime[name] = info.ModTime()
+	}
+	sort.SliceStable(files, func(i, j int) bool { return mtime[files[i]].Before(mtime[files[j]]) })
+	return files, nil
+}
+
+// backupsOf 列出 name 的滚动历史文件：
+// lumberjack 的 app-2006-01-02T15-04-05.000.log、RotatingFile 的 app.N.log，以及它们的 .gz。
+// 按文件名精确匹配，同目录下的 app-audit-*.log 等其他文件不会被当作历史文件
+func backupsOf(name string) []string {
+	name = strings.TrimSuffix(name, ".gz")
+	dir, base := filepath.Dir(name), filepath.Base(name)
+	ext := filepath.Ext(base)
+	re := regexp.MustCompile("^" + regexp.QuoteMeta(strings.TrimSuffix(base, ext)) +
+		`(?:-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}|\.\d+)` +
+		regexp.QuoteMeta(ext) + `(?:\.gz)?$`)
+
+	entries, _ := os.ReadDir(dir)
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() && re.MatchString(e.Name()) {
+			out = append(out, filepath.Join(dir, e.Name()))
+		}
+	}
+	return out
+}
+
+func scanFile(name string, key []byte, c *collector) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return scanReader(name, r, key, c)
+}
+
+// scanReader 读取一个文件的内容，加密内容按开头自动识别并解密
+func scanReader(name string, r io.Reader, key []byte, c *collector) error {
+	br := bufio.NewReader(r)
+	if head, _ := br.Peek(4); aes.IsEncrypted(head) {
+		if key == nil {
+			return fmt.Errorf("文件已加密，请使用 -key 或 LOG_ENCRYPT_KEY 指定密钥")
+		}
+		dr, err := aes.NewReader(br, key)
+		if err != nil {
+			return err
+		}
+		sr := &skipCorrupt{r: dr}
+		err = scan(sr, c)
+		c.flush()
+		if sr.corrupt > 0 || sr.gaps > 0 {
+			fmt.Fprintf(os.Stderr, "logq: %s: %d 个加密块无法解密（密钥错误、损坏或被篡改），%d 处块缺失或乱序\n",
+				name, sr.corrupt, sr.gaps)
+		}
+		return err
+	}
+	err := scan(br, c)
+	c.flush()
+	return err
+}
+
+// skipCorrupt 跳过无法解密的块并计数，继续读取之后完好的块
+type skipCorrupt struct {
+	r       io.Reader
+	corrupt int
+	gaps    int
+}
+
+func (s *skipCorrupt) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		switch {
+		case errors.Is(err, aes.ErrCorrupt):
+			s.corrupt++
+		case errors.Is(err, aes.ErrChunkGap):
+			s.gaps++
+		default:
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func parseKey(s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return aes.ParseKey(s)
+}
+
+func scan(r io.Reader, c *collector) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		c.line(strings.TrimRight(sc.Text(), "\r"))
+	}
+	return sc.Err()
+}
+
+// flushAfter 跟踪模式下每条记录立即输出
+func flushAfter(emit func(*record), out *bufio.Writer) func(*record) {
+	return func(r *record) {
+		emit(r)
+		out.Flush()
+	}
+}
+
+// followFile 从头读取文件并持续跟踪新增内容；文件被改名滚动或截断后重新打开
+func followFile(ctx context.Context, name string, c *collector) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+	head := make([]byte, 4)
+	if n, _ := f.ReadAt(head, 0); aes.IsEncrypted(head[:n]) {
+		return fmt.Errorf("加密文件不支持 -f")
+	}
+
+	br := bufio.NewReader(f)
+	var partial string
+	var offset int64
+	for {
+		line, err := br.ReadString('\n')
+		offset += int64(len(line))
+		if err == nil {
+			c.line(strings.TrimRight(partial+line, "\r\n"))
+			partial = ""
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		partial += line
+		c.flush()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		info, err := os.Stat(name)
+		if err != nil {
+			continue // 滚动过程中文件可能暂时不存在
+		}
+		cur, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if os.SameFile(info, cur) && info.Size() >= offset {
+			continue
+		}
+		nf, err := os.Open(name)
+		if err != nil {
+			continue
+		}
+		f.Close()
+		f, br, partial, offset = nf, bufio.NewReader(nf), "", 0
+	}
+}
+
+// =============================
+// 统计
+// =============================
+
+var levels = []logger.Level{logger.DebugLevel, logger.InfoLevel, logger.WarnLevel, logger.ErrorLevel, logger.FatalLevel}
+
+type stats struct {
+	total    int
+	unparsed int
+	levels   map[logger.Level]int
+	minutes  map[time.Time]map[logger.Level]int
+}
+
+func newStats() *stats {
+	return &stats{
+		levels:  make(map[logger.Level]int),
+		minutes: make(map[time.Time]map[logger.Level]int),
+	}
+}
+
+func (s *stats) add(r *record) {
+	s.total++
+	if !r.Parsed {
+		s.unparsed++
+		return
+	}
+	s.levels[r.Level]++
+	m := r.Time.Local().Truncate(time.Minute)
+	if s.minutes[m] == nil {
+		s.minutes[m] = make(map[logger.Level]int)
+	}
+	s.minutes[m][r.Level]++
+}
+
+func (s *stats) print(w io.Writer) {
+	fmt.Fprintf(w, "total: %d", s.total)
+	if s.unparsed > 0 {
+		fmt.Fprintf(w, " (unparsed: %d)", s.unparsed)
+	}
+	fmt.Fprintln(w)
+	for _, l := range levels {
+		fmt.Fprintf(w, "  %-5s %d\n", l, s.levels[l])
+	}
+
+	keys := make([]time.Time, 0, len(s.minutes))
+	for m := range s.minutes {
+		keys = append(keys, m)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Before(keys[j]) })
+
+	fmt.Fprintf(w, "\n%-16s %7s", "minute", "total")
+	for _, l := range levels {
+		fmt.Fprintf(w, " %7s", l)
+	}
+	fmt.Fprintln(w)
+	for _, m := range keys {
+		counts := s.minutes[m]
+		total := 0
+		for _, n := range counts {
+			total += n
+		}
+		fmt.Fprintf(w, "%-16s %7d", m.Format("2006-01-02 15:04"), total)
+		for _, l := range levels {
+			fmt.Fprintf(w, " %7d", counts[l])
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBackupsOf(t *testing.T) {
+	dir := t.TempDir()
+	want := []string{
+		"app-2026-10-12T08-30-00.000.log",
+		"app-2026-10-12T08-30-00.000.log.gz",
+		"app.1.log",
+		"app.12.log.gz",
+	}
+	others := []string{
+		"app.log",
+		"app-audit-2026-10-12T08-30-00.000.log",
+		"app-audit.1.log",
+		"app-2026-10-12.log",
+		"app.x.log",
+		"app.1.log.bak",
+	}
+	for _, name := range append(append([]string(nil), want...), others...) {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	for _, path := range backupsOf(filepath.Join(dir, "app.log")) {
+		got = append(got, filepath.Base(path))
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("backups = %v, want %v", got, want)
+	}
+}
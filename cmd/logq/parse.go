@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"utils/logger"
+)
+
+// record 一条日志，文本格式下调用栈等续行会并入同一条记录
+type record struct {
+	Time    time.Time
+	Level   logger.Level
+	TraceID string
+	Message string
+	Raw     string
+	Parsed  bool // false 表示无法识别格式的行
+}
+
+// 文本格式的行首：logger.TextFormatter 的 "2006-01-02 15:04:05 [INFO] "
+// 与全局 Log 的 "[2006-01-02 15:04:05] [INFO] "
+var (
+	textHeadRe  = regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\]? \[([A-Za-z]+)\] ?(.*)$`)
+	textTraceRe = regexp.MustCompile(`^\[traceID: ([^\s\]]+)(?: spanID: [^\s\]]+)?\] ?`)
+)
+
+// parseLine 解析一行日志，不是记录开头（如调用栈的续行）时返回 false
+func parseLine(line string) (record, bool) {
+	if strings.HasPrefix(line, "{") {
+		return parseJSON(line)
+	}
+	return parseText(line)
+}
+
+func parseText(line string) (record, bool) {
+	m := textHeadRe.FindStringSubmatch(line)
+	if m == nil {
+		return record{}, false
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", m[1], time.Local)
+	if err != nil {
+		return record{}, false
+	}
+	level, err := logger.ParseLevel(m[2])
+	if err != nil {
+		return record{}, false
+	}
+	r := record{Time: t, Level: level, Message: m[3], Raw: line, Parsed: true}
+	if tm := textTraceRe.FindStringSubmatch(r.Message); tm != nil {
+		r.TraceID = tm[1]
+		r.Message = r.Message[len(tm[0]):]
+	}
+	return r, true
+}
+
+func parseJSON(line string) (record, bool) {
+	var v struct {
+		Level   string `json:"level"`
+		Time    string `json:"time"`
+		TraceID string `json:"trace_id"`
+		Msg     string `json:"msg"`
+	}
+	if err := json.Unmarshal([]byte(line), &v); err != nil || v.Level == "" {
+		return record{}, false
+	}
+	level, err := logger.ParseLevel(v.Level)
+	if err != nil {
+		return record{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, v.Time)
+	if err != nil {
+		return record{}, false
+	}
+	return record{Time: t, Level: level, TraceID: v.TraceID, Message: v.Msg, Raw: line, Parsed: true}, true
+}
+
+// collector 按行组装记录，遇到下一条记录的开头时输出上一条
+type collector struct {
+	cur  *record
+	emit func(*record)
+}
+
+func (c *collector) line(s string) {
+	if r, ok := parseLine(s); ok {
+		c.flush()
+		c.cur = &r
+		return
+	}
+	if c.cur != nil {
+		c.cur.Raw += "\n" + s
+		return
+	}
+	c.emit(&record{Raw: s})
+}
+
+// flush 输出尚未输出的记录
+func (c *collector) flush() {
+	if c.cur != nil {
+		c.emit(c.cur)
+		c.cur = nil
+	}
+}
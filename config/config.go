@@ -1,70 +1,174 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
-
-	"github.com/BurntSushi/toml"
+	"sync/atomic"
 )
 
 // 全局配置实例
-var (
-	cfg  *Config
-	once sync.Once
-)
+var global struct {
+	mu     sync.Mutex // 串行化 LoadConfig/Reload/Reset
+	loaded bool       // 是否已调用过 LoadConfig
+	files  []string   // LoadConfig 传入的文件，Reload 时重新读取
+	err    error      // LoadConfig 的错误，之后的调用原样返回
+
+	cfg atomic.Pointer[Config]
+
+	subs     []subscriber // OnChange 注册的回调，按注册顺序调用
+	nextSub  int
+	watchers []func() // Watch 的 stop，Reset 时一并停止
+}
+
+// ErrNotLoaded 尚未调用 LoadConfig，或 LoadConfig 失败
+var ErrNotLoaded = errors.New("配置尚未初始化，请先调用 LoadConfig()")
 
 // Config 结构体（可以根据需要扩展）
 type Config struct {
 	App      AppConfig      `toml:"app"`
 	Database DatabaseConfig `toml:"database"`
 	Redis    RedisConfig    `toml:"redis"`
+	Log      LogConfig      `toml:"log"`
 	// 可以继续扩展其他子配置...
 }
 
 type AppConfig struct {
 	Name string `toml:"name"`
-	Port int    `toml:"port"`
-	Mode string `toml:"mode"`
+	Port int    `toml:"port" default:"8080" validate:"min=1,max=65535"`
+	Mode string `toml:"mode" default:"dev" validate:"omitempty,oneof=dev test prod"`
 }
 
 type DatabaseConfig struct {
 	Host     string `toml:"host"`
-	Port     int    `toml:"port"`
+	Port     int    `toml:"port" default:"3306" validate:"min=1,max=65535"`
 	User     string `toml:"user"`
 	Password string `toml:"password"`
 	DBName   string `toml:"dbname"`
 }
 
+// RedisConfig 各段为共享结构，不是每个应用都会用到，因此不加 required；
+// 必须配置 Redis 的应用在自己的结构体或 Validator 中检查 Addr
 type RedisConfig struct {
 	Addr     string `toml:"addr"`
 	Password string `toml:"password"`
-	DB       int    `toml:"db"`
+	DB       int    `toml:"db" validate:"min=0"`
+}
+
+// LogConfig 日志级别配置，示例：
+//
+//	[log]
+//	level = "info"
+//	[log.modules]
+//	xmysql = "debug"
+type LogConfig struct {
+	Level   string            `toml:"level"`   // 为空时不修改 logger 当前的级别（如 LOG_LEVEL）
+	Modules map[string]string `toml:"modules"` // 模块名 -> 级别，对应 logger.Named 的名称
+}
+
+// Validate 校验日志级别，端口等字段的规则见 validate tag
+func (c *Config) Validate() error {
+	var errs []error
+	if !validLogLevel(c.Log.Level) {
+		errs = append(errs, fmt.Errorf("log.level: 未知的日志级别 %q", c.Log.Level))
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Log.Modules)) {
+		if lvl := c.Log.Modules[name]; !validLogLevel(lvl) {
+			errs = append(errs, fmt.Errorf("log.modules.%s: 未知的日志级别 %q", name, lvl))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validLogLevel 与 logger.ParseLevel 接受的级别一致（config 不能依赖 logger）
+func validLogLevel(s string) bool {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "", "DEBUG", "INFO", "WARN", "WARNING", "ERROR", "FATAL":
+		return true
+	}
+	return false
 }
 
-// LoadConfig 支持加载多个 toml 文件（后面的会覆盖前面的同名字段）
+// LoadConfig 支持加载多个 toml 文件（后面的会覆盖前面的同名字段），
+// 最后用环境变量覆盖，如 APP_DATABASE_PASSWORD，规则见 ApplyEnv。
+// 结果保存为全局配置，需要自定义结构体或多份配置时使用 Load。
+//
+// 全局配置只加载一次：之后以相同的文件调用返回同一个结果（包括第一次的错误），
+// 以不同的文件调用返回错误；重新读取用 Reload，更换文件先调用 Reset
 func LoadConfig(files ...string) (*Config, error) {
-	var err error
-	once.Do(func() {
-		cfg = &Config{}
-		for _, file := range files {
-			if _, err = os.Stat(file); os.IsNotExist(err) {
-				err = fmt.Errorf("配置文件不存在: %s", file)
-				return
-			}
-			if _, err = toml.DecodeFile(file, cfg); err != nil {
-				err = fmt.Errorf("解析配置文件失败 %s: %v", file, err)
-				return
-			}
+	global.mu.Lock()
+	defer global.mu.Unlock()
+	if global.loaded {
+		if !slices.Equal(global.files, files) {
+			return global.cfg.Load(), fmt.Errorf("配置已由 LoadConfig(%q) 加载，更换文件请先调用 Reset()", global.files)
 		}
-	})
-	return cfg, err
+		return global.cfg.Load(), global.err
+	}
+
+	global.loaded = true
+	global.files = slices.Clone(files)
+	c, err := Load[Config](Files(files...), Env(EnvPrefix))
+	if err != nil {
+		global.err = err
+		return nil, err
+	}
+	global.cfg.Store(c)
+	return c, nil
+}
+
+// Reload 重新读取 LoadConfig 的文件与环境变量，校验通过后整体替换全局配置并通知 OnChange 的订阅者，
+// 已通过 GetConfig 取得的 *Config 不会被修改；失败时保留原配置并返回错误
+func Reload() (*Config, error) {
+	global.mu.Lock()
+	if !global.loaded {
+		global.mu.Unlock()
+		return nil, ErrNotLoaded
+	}
+	c, err := Load[Config](Files(global.files...), Env(EnvPrefix))
+	if err != nil {
+		global.mu.Unlock()
+		return nil, err
+	}
+	global.err = nil
+	old := global.cfg.Swap(c)
+	subs := slices.Clone(global.subs)
+	global.mu.Unlock()
+
+	notify(subs, old, c)
+	return c, nil
+}
+
+// Reset 清空全局配置、OnChange 回调并停止 Watch，之后可以重新调用 LoadConfig，主要用于测试
+func Reset() {
+	global.mu.Lock()
+	defer global.mu.Unlock()
+	global.loaded = false
+	global.files = nil
+	global.err = nil
+	global.cfg.Store(nil)
+	global.subs = nil
+	for _, stop := range global.watchers {
+		stop()
+	}
+	global.watchers = nil
+}
+
+// GetConfig 获取全局配置，尚未加载或加载失败时返回 ErrNotLoaded
+func GetConfig() (*Config, error) {
+	if c := global.cfg.Load(); c != nil {
+		return c, nil
+	}
+	return nil, ErrNotLoaded
 }
 
-// GetConfig 获取全局配置
-func GetConfig() *Config {
-	if cfg == nil {
-		panic("配置尚未初始化，请先调用 LoadConfig()")
+// MustGetConfig 同 GetConfig，出错时 panic
+func MustGetConfig() *Config {
+	c, err := GetConfig()
+	if err != nil {
+		panic(err)
 	}
-	return cfg
+	return c
 }
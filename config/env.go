@@ -0,0 +1,201 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix LoadConfig 读取环境变量时使用的前缀
+var EnvPrefix = "APP"
+
+// 环境变量覆盖
+//
+// 变量名由前缀与 toml tag 逐级拼接后转大写，"." 与 "-" 替换为 "_"，如：
+//
+//	Database.Password  toml:"password"  ->  APP_DATABASE_PASSWORD
+//	App.Port           toml:"port"      ->  APP_APP_PORT
+//
+// 字段可以用 env tag 指定完整的变量名（不加前缀），env:"-" 表示不读取环境变量；
+// 结构体字段上的 env tag 作为其子字段的前缀：
+//
+//	Password string `toml:"password" env:"DB_PASSWORD"`
+//
+// 支持 string、整数、浮点数、bool、time.Duration、实现了 encoding.TextUnmarshaler 的类型，
+// 切片以逗号分隔（"a,b,c"），map[string]T 以 "k1=v1,k2=v2" 表示。
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// ApplyEnv 用环境变量覆盖 v（结构体指针）中的字段，prefix 为空时变量名不加前缀
+func ApplyEnv(v interface{}, prefix string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ApplyEnv 需要结构体指针，实际为 %T", v)
+	}
+	return applyEnv(rv.Elem(), envName(prefix))
+}
+
+func applyEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		name, explicit := sf.Tag.Lookup("env")
+		if name == "-" {
+			continue
+		}
+		if sf.Anonymous && !explicit && sf.Tag.Get("toml") == "" && isNestedStruct(sf.Type) {
+			// 未加 tag 的嵌入结构体与 toml 一致，字段提升到外层
+			if err := applyEnv(fv, prefix); err != nil {
+				return err
+			}
+			continue
+		}
+		if !explicit {
+			name = joinEnv(prefix, fieldKey(sf))
+		}
+
+		if isNestedStruct(sf.Type) {
+			if err := applyEnv(fv, name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setValue(fv, raw); err != nil {
+			return fmt.Errorf("环境变量 %s 解析失败: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// fieldKey 取 toml tag 中的名称，没有 tag 时使用字段名
+func fieldKey(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("toml"); tag != "" {
+		if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+func joinEnv(prefix, key string) string {
+	if prefix == "" {
+		return envName(key)
+	}
+	return prefix + "_" + envName(key)
+}
+
+func envName(s string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(s))
+}
+
+// isNestedStruct 判断是否按子配置展开，time.Time 等可从文本解析的结构体视为单个值
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func setValue(v reflect.Value, raw string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(raw), 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(raw), 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(raw), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := splitList(raw)
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(s.Index(i), item); err != nil {
+				return fmt.Errorf("第 %d 项: %v", i+1, err)
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("不支持的 map 类型 %s", v.Type())
+		}
+		m := reflect.MakeMap(v.Type())
+		for _, item := range splitList(raw) {
+			key, val, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("%q 缺少 '='", item)
+			}
+			ev := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(ev, strings.TrimSpace(val)); err != nil {
+				return fmt.Errorf("键 %s: %v", key, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)).Convert(v.Type().Key()), ev)
+		}
+		v.Set(m)
+	case reflect.Ptr:
+		pv := reflect.New(v.Type().Elem())
+		if err := setValue(pv.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(pv)
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", v.Type())
+	}
+	return nil
+}
+
+// splitList 按逗号拆分并去掉空白，空字符串得到空列表
+func splitList(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	parts := strings.Split(raw, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
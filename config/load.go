@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Source 配置来源，Load 按顺序依次作用于同一个结构体（v 为结构体指针），后面的覆盖前面的同名字段
+type Source func(v interface{}) error
+
+// File toml 文件，文件不存在时报错
+func File(path string) Source {
+	return func(v interface{}) error {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("配置文件不存在: %s", path)
+		}
+		if _, err := toml.DecodeFile(path, v); err != nil {
+			return fmt.Errorf("解析配置文件失败 %s: %v", path, err)
+		}
+		return nil
+	}
+}
+
+// OptionalFile 同 File，文件不存在时跳过，适合本地覆盖用的 config.local.toml
+func OptionalFile(path string) Source {
+	return func(v interface{}) error {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil
+		}
+		return File(path)(v)
+	}
+}
+
+// Files 多个 toml 文件，等价于依次传入 File
+func Files(paths ...string) Source {
+	return func(v interface{}) error {
+		for _, path := range paths {
+			if err := File(path)(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// TOML 内存中的 toml 文本，便于测试或内置默认配置
+func TOML(data string) Source {
+	return func(v interface{}) error {
+		if _, err := toml.Decode(data, v); err != nil {
+			return fmt.Errorf("解析配置失败: %v", err)
+		}
+		return nil
+	}
+}
+
+// Env 环境变量，规则见 ApplyEnv
+func Env(prefix string) Source {
+	return func(v interface{}) error { return ApplyEnv(v, prefix) }
+}
+
+// Load 按 sources 的顺序填充一个新的 T，T 通常是应用自定义的配置结构体：
+//
+//	type Settings struct {
+//		config.Config          // 复用 App/Database/Redis/Log 段
+//		Kafka KafkaConfig `toml:"kafka"`
+//	}
+//	cfg, err := config.Load[Settings](config.File("config.toml"), config.Env("APP"))
+//
+// 每次调用都返回独立的实例，不影响 LoadConfig/GetConfig 的全局配置。
+// 读取前按 default tag 填充默认值，全部 sources 读取完后按 validate tag 校验，
+// *T 实现了 Validator 时再调用 Validate，所有问题合并为一个错误返回
+func Load[T any](sources ...Source) (*T, error) {
+	v := new(T)
+	if reflect.TypeOf(v).Elem().Kind() == reflect.Struct {
+		if err := ApplyDefaults(v); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range sources {
+		if err := s(v); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	if reflect.TypeOf(v).Elem().Kind() == reflect.Struct {
+		errs = append(errs, ValidateStruct(v))
+	}
+	if vd, ok := any(v).(Validator); ok {
+		errs = append(errs, vd.Validate())
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("配置校验失败: %w", err)
+	}
+	return v, nil
+}
+
+// Validator 由配置结构体实现，Load 与热加载在使用新配置前调用
+type Validator interface {
+	Validate() error
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 默认值与校验规则
+//
+// default tag 在读取配置文件之前写入，文件与环境变量中出现的字段会覆盖它，格式同环境变量（见 ApplyEnv）：
+//
+//	Port    int           `toml:"port" default:"8080"`
+//	Timeout time.Duration `toml:"timeout" default:"3s"`
+//
+// validate tag 在全部来源读取完成后检查，多条规则以逗号分隔：
+//
+//	required    不能为零值（空字符串、0、空切片等）
+//	omitempty   零值时跳过其余规则
+//	min=N max=N 数值的范围；字符串、切片、map 为长度；time.Duration 可写作 min=1s
+//	oneof=a b c 取值必须是其中之一
+//
+// 所有不满足的字段一起以 ValidationErrors 返回，键名为 toml 路径，如 app.port。
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Key     string // toml 路径，如 app.port
+	Rule    string // 未通过的规则，如 min=1
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Key + ": " + e.Message
+}
+
+// ValidationErrors 校验失败的全部字段，按结构体中字段的顺序排列
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, fe := range e {
+		lines[i] = fe.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ApplyDefaults 按 default tag 为 v（结构体指针）中的零值字段赋值
+func ApplyDefaults(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ApplyDefaults 需要结构体指针，实际为 %T", v)
+	}
+	return applyDefaults(rv.Elem(), "")
+}
+
+func applyDefaults(v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		key := fieldPath(path, sf)
+		if isNestedStruct(sf.Type) {
+			if err := applyDefaults(fv, key); err != nil {
+				return err
+			}
+			continue
+		}
+		def, ok := sf.Tag.Lookup("default")
+		if !ok || !fv.IsZero() {
+			continue
+		}
+		if err := setValue(fv, def); err != nil {
+			return fmt.Errorf("%s: 默认值 %q 无效: %v", key, def, err)
+		}
+	}
+	return nil
+}
+
+// ValidateStruct 按 validate tag 校验 v（结构体或结构体指针），返回 ValidationErrors 或 nil
+func ValidateStruct(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("ValidateStruct 需要结构体，实际为 %T", v)
+	}
+	var errs ValidationErrors
+	validateStruct(rv, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateStruct(v reflect.Value, path string, errs *ValidationErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		key := fieldPath(path, sf)
+		if tag := sf.Tag.Get("validate"); tag != "" {
+			validateField(fv, key, tag, errs)
+		}
+		switch {
+		case isNestedStruct(sf.Type):
+			validateStruct(fv, key, errs)
+		case sf.Type.Kind() == reflect.Slice && isNestedStruct(sf.Type.Elem()):
+			for j := 0; j < fv.Len(); j++ {
+				validateStruct(fv.Index(j), fmt.Sprintf("%s[%d]", key, j), errs)
+			}
+		}
+	}
+}
+
+func validateField(v reflect.Value, key, tag string, errs *ValidationErrors) {
+	fail := func(rule, format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Key: key, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "":
+		case "omitempty":
+			if v.IsZero() {
+				return
+			}
+		case "required":
+			if isEmpty(v) {
+				fail(rule, "不能为空")
+				return
+			}
+		case "min", "max":
+			n, bound, ok := measure(v, arg)
+			if !ok {
+				fail(rule, "无效的校验规则 %q", rule)
+				continue
+			}
+			what, actual := "不能", formatValue(v)
+			if isLength(v) {
+				// 只报告长度，避免密码等内容出现在错误中
+				what, actual = "长度不能", strconv.FormatFloat(n, 'f', -1, 64)
+			}
+			if name == "min" && n < bound {
+				fail(rule, "%s小于 %s，实际为 %s", what, arg, actual)
+			}
+			if name == "max" && n > bound {
+				fail(rule, "%s大于 %s，实际为 %s", what, arg, actual)
+			}
+		case "oneof":
+			options := strings.Fields(arg)
+			actual := formatValue(v)
+			found := false
+			for _, o := range options {
+				if o == actual {
+					found = true
+					break
+				}
+			}
+			if !found {
+				fail(rule, "必须是 %s 之一，实际为 %q", strings.Join(options, "、"), actual)
+			}
+		default:
+			fail(rule, "未知的校验规则 %q", rule)
+		}
+	}
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	}
+	return v.IsZero()
+}
+
+func isLength(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// measure 返回用于 min/max 比较的值与界限
+func measure(v reflect.Value, arg string) (n, bound float64, ok bool) {
+	if v.Type() == durationType {
+		if d, err := time.ParseDuration(arg); err == nil {
+			return float64(v.Int()), float64(d), true
+		}
+	}
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), bound, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), bound, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), bound, true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), bound, true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), bound, true
+	}
+	return 0, 0, false
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// fieldPath 拼接 toml 路径，未加 tag 的嵌入结构体与 toml 一致，字段提升到外层
+func fieldPath(path string, sf reflect.StructField) string {
+	if sf.Anonymous && sf.Tag.Get("toml") == "" && isNestedStruct(sf.Type) {
+		return path
+	}
+	if path == "" {
+		return fieldKey(sf)
+	}
+	return path + "." + fieldKey(sf)
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type subscriber struct {
+	id int
+	fn func(old, new *Config)
+}
+
+// OnChange 注册全局配置变更的回调，Reload 或 Watch 替换配置后按注册顺序同步调用，
+// 内容未变化时不调用。old 与 new 都不应被修改。返回的 cancel 用于取消注册
+//
+//	config.OnChange(func(old, new *config.Config) {
+//		if err := logger.ApplyConfig(logger.Log, new.Log); err != nil { ... }
+//	})
+func OnChange(fn func(old, new *Config)) (cancel func()) {
+	global.mu.Lock()
+	defer global.mu.Unlock()
+	global.nextSub++
+	id := global.nextSub
+	global.subs = append(global.subs, subscriber{id: id, fn: fn})
+	return func() {
+		global.mu.Lock()
+		defer global.mu.Unlock()
+		for i, s := range global.subs {
+			if s.id == id {
+				global.subs = append(global.subs[:i:i], global.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func notify(subs []subscriber, old, new *Config) {
+	if old == nil || reflect.DeepEqual(old, new) {
+		return
+	}
+	for _, s := range subs {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Fprintf(os.Stderr, "config OnChange 回调 panic: %v\n", r)
+				}
+			}()
+			s.fn(old, new)
+		}()
+	}
+}
+
+// WatchOptions Watch 的配置
+type WatchOptions struct {
+	// Interval 检查间隔，默认 2s
+	Interval time.Duration
+	// OnError 新配置读取或校验失败时调用，此时继续使用原配置；默认输出到 stderr。
+	// 同一份有误的文件只报告一次，修正后自动加载
+	OnError func(err error)
+}
+
+// Watch 轮询 LoadConfig 的文件，修改时间或大小变化时比较内容哈希，内容变化后调用 Reload：
+// 新配置解析并校验通过才会替换，否则保留原配置并通过 OnError 报告。
+// 需先调用 LoadConfig，返回的 stop 用于停止监听
+func Watch(opts WatchOptions) (stop func(), err error) {
+	if opts.Interval <= 0 {
+		opts.Interval = 2 * time.Second
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(err error) {
+			fmt.Fprintf(os.Stderr, "配置热加载失败，继续使用原配置: %v\n", err)
+		}
+	}
+
+	global.mu.Lock()
+	if !global.loaded || global.cfg.Load() == nil {
+		global.mu.Unlock()
+		return nil, ErrNotLoaded
+	}
+	w := &watcher{opts: opts, files: make([]fileState, len(global.files)), done: make(chan struct{})}
+	for i, path := range global.files {
+		w.files[i] = statFile(path)
+		w.files[i].sum = hashFile(path)
+	}
+	global.watchers = append(global.watchers, w.stop)
+	global.mu.Unlock()
+
+	go w.run()
+	return w.stop, nil
+}
+
+type fileState struct {
+	path    string
+	exists  bool
+	modTime time.Time
+	size    int64
+	sum     []byte
+}
+
+type watcher struct {
+	opts  WatchOptions
+	files []fileState
+
+	once sync.Once
+	done chan struct{}
+}
+
+func (w *watcher) stop() {
+	w.once.Do(func() { close(w.done) })
+}
+
+func (w *watcher) run() {
+	t := time.NewTicker(w.opts.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-t.C:
+			if w.changed() {
+				if _, err := Reload(); err != nil {
+					select {
+					case <-w.done: // Reset 之后的 ErrNotLoaded 不再报告
+						return
+					default:
+					}
+					w.opts.OnError(err)
+				}
+			}
+		}
+	}
+}
+
+// changed 检查文件是否变化并更新记录，mtime 与大小都未变时不读取文件
+func (w *watcher) changed() bool {
+	changed := false
+	for i, old := range w.files {
+		cur := statFile(old.path)
+		if cur.exists == old.exists && cur.modTime.Equal(old.modTime) && cur.size == old.size {
+			continue
+		}
+		cur.sum = hashFile(old.path)
+		if cur.exists != old.exists || !bytes.Equal(cur.sum, old.sum) {
+			changed = true
+		}
+		w.files[i] = cur
+	}
+	return changed
+}
+
+func statFile(path string) fileState {
+	st := fileState{path: path}
+	if fi, err := os.Stat(path); err == nil {
+		st.exists = true
+		st.modTime = fi.ModTime()
+		st.size = fi.Size()
+	}
+	return st
+}
+
+func hashFile(path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
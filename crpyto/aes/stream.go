@@ -0,0 +1,281 @@
+package aes
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// 流式认证加密（AES-GCM 分块），用于加密日志等追加写入的文件。
+//
+// 数据由若干可独立解密的块组成，每块：
+//
+//	"ELG1" | stream ID 8 字节 | 序号 8 字节 | nonce 12 字节 | 密文长度 4 字节 | 密文（含 16 字节 tag）
+//
+// 块头作为附加数据参与认证，nonce 随机生成。每块可以单独解密，因此文件按大小/时间滚动、
+// 重启后追加写入都不影响读取；同一 stream 内序号不连续（块被删除或调换）时 Reader 报错。
+// 文件末尾整块被截掉无法发现。
+
+const (
+	chunkMagic       = "ELG1"
+	chunkHeaderSize  = 4 + 8 + 8 + 12 + 4
+	maxChunkSize     = 16 << 20
+	DefaultChunkSize = 64 << 10
+)
+
+var (
+	// ErrNoKey 未提供密钥，流式加密不使用本包的默认密钥（公开在源码中）
+	ErrNoKey = errors.New("encryption key required")
+	// ErrCorrupt 块无法解密（密钥错误、数据损坏或被篡改）或不完整
+	ErrCorrupt = errors.New("encrypted chunk corrupt or tampered")
+	// ErrChunkGap 同一 stream 的块序号不连续
+	ErrChunkGap = errors.New("encrypted chunk missing or reordered")
+)
+
+// IsEncrypted 判断数据开头是否为加密流
+func IsEncrypted(head []byte) bool {
+	return bytes.HasPrefix(head, []byte(chunkMagic))
+}
+
+// GenerateKey 生成随机的 AES-256 密钥
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ParseKey 解析十六进制密钥，长度须为 16、24 或 32 字节
+func ParseKey(s string) ([]byte, error) {
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("密钥解码失败: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	}
+	return nil, fmt.Errorf("密钥长度错误: %d 字节", len(key))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) == 0 {
+		return nil, ErrNoKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// =============================
+// Writer
+// =============================
+
+// Writer 将明文分块加密后写入 w，每块用一次 Write 写出，不会被滚动切分到两个文件
+type Writer struct {
+	w         io.Writer
+	aead      cipher.AEAD
+	stream    [8]byte
+	seq       uint64
+	chunkSize int
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewWriter 创建加密 Writer，key 须为 16、24 或 32 字节，为空时返回 ErrNoKey
+func NewWriter(w io.Writer, key []byte) (*Writer, error) {
+	return NewWriterSize(w, key, DefaultChunkSize)
+}
+
+// NewWriterSize 同 NewWriter，明文攒满 chunkSize 字节时写出一块
+func NewWriterSize(w io.Writer, key []byte, chunkSize int) (*Writer, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if chunkSize <= 0 || chunkSize > maxChunkSize-aead.Overhead() {
+		chunkSize = DefaultChunkSize
+	}
+	ew := &Writer{w: w, aead: aead, chunkSize: chunkSize}
+	if _, err := rand.Read(ew.stream[:]); err != nil {
+		return nil, err
+	}
+	return ew, nil
+}
+
+// Write 缓冲明文，攒满 chunkSize 时写出一块。单次 Write 的数据不会被拆到两块中，
+// 因此逐条写入日志时块边界总在两条日志之间
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(p)
+	if len(w.buf) > 0 && len(w.buf)+len(p) > w.chunkSize {
+		if err := w.flushLocked(); err != nil {
+			return 0, err
+		}
+	}
+	for len(p) > maxChunkSize-w.aead.Overhead() {
+		// 超大的单次写入只能拆分
+		k := maxChunkSize - w.aead.Overhead()
+		w.buf = append(w.buf, p[:k]...)
+		if err := w.flushLocked(); err != nil {
+			return 0, err
+		}
+		p = p[k:]
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.chunkSize {
+		if err := w.flushLocked(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// Flush 将缓冲的明文加密为一块写出
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flushLocked()
+}
+
+func (w *Writer) flushLocked() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	out := make([]byte, chunkHeaderSize, chunkHeaderSize+len(w.buf)+w.aead.Overhead())
+	copy(out, chunkMagic)
+	copy(out[4:], w.stream[:])
+	binary.BigEndian.PutUint64(out[12:], w.seq)
+	nonce := out[20:32]
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint32(out[32:], uint32(len(w.buf)+w.aead.Overhead()))
+	out = w.aead.Seal(out, nonce, w.buf, out[:chunkHeaderSize])
+	if _, err := w.w.Write(out); err != nil {
+		return err
+	}
+	w.seq++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Close 写出剩余数据，w 实现了 io.Closer 时一并关闭
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if c, ok := w.w.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// =============================
+// Reader
+// =============================
+
+// Reader 解密 Writer 写出的数据，支持多段加密流首尾相接（如多次启动追加写入同一文件）。
+// 遇到损坏的块时 Read 返回 ErrCorrupt（或 ErrChunkGap），之后的 Read 从下一个完好的块继续
+type Reader struct {
+	r     *bufio.Reader
+	aead  cipher.AEAD
+	plain []byte
+	next  map[[8]byte]uint64 // 各 stream 期望的下一个序号
+}
+
+// NewReader 创建解密 Reader，key 为空时返回 ErrNoKey
+func NewReader(r io.Reader, key []byte) (*Reader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{r: bufio.NewReader(r), aead: aead, next: make(map[[8]byte]uint64)}, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if err := r.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *Reader) readChunk() error {
+	header := make([]byte, chunkHeaderSize)
+	n, err := io.ReadFull(r.r, header)
+	if err == io.EOF {
+		return io.EOF
+	}
+	if err != nil {
+		return r.resync(header[:n], ErrCorrupt)
+	}
+	if string(header[:4]) != chunkMagic {
+		return r.resync(header, ErrCorrupt)
+	}
+	size := binary.BigEndian.Uint32(header[32:])
+	if size < uint32(r.aead.Overhead()) || size > maxChunkSize {
+		return r.resync(header, ErrCorrupt)
+	}
+	body := make([]byte, size)
+	if n, err := io.ReadFull(r.r, body); err != nil {
+		return r.resync(append(header, body[:n]...), ErrCorrupt)
+	}
+	plain, err := r.aead.Open(body[:0], header[20:32], body, header)
+	if err != nil {
+		return r.resync(append(header, body...), ErrCorrupt)
+	}
+
+	var stream [8]byte
+	copy(stream[:], header[4:12])
+	seq := binary.BigEndian.Uint64(header[12:20])
+	want, seen := r.next[stream]
+	r.next[stream] = seq + 1
+	r.plain = plain
+	if seen && seq != want {
+		return ErrChunkGap
+	}
+	return nil
+}
+
+// resync 在已读出的数据中查找下一个块头，找到后将其放回输入，返回 err
+func (r *Reader) resync(consumed []byte, err error) error {
+	if len(consumed) > 1 {
+		if i := bytes.Index(consumed[1:], []byte(chunkMagic)); i >= 0 {
+			rest := append([]byte(nil), consumed[i+1:]...)
+			r.r = bufio.NewReader(io.MultiReader(bytes.NewReader(rest), r.r))
+			return err
+		}
+	}
+	// 块头可能跨越已读数据的末尾，丢弃到下一个 magic 之前的数据
+	for {
+		b, rerr := r.r.Peek(len(chunkMagic))
+		if rerr != nil {
+			if len(consumed) == 0 && len(b) == 0 {
+				return io.EOF
+			}
+			_, _ = r.r.Discard(len(b))
+			return err
+		}
+		if string(b) == chunkMagic {
+			return err
+		}
+		_, _ = r.r.Discard(1)
+	}
+}
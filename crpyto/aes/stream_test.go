@@ -0,0 +1,155 @@
+package aes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, 32)
+
+// writeChunks 每行写成一块，返回数据与各块的结束偏移
+func writeChunks(t *testing.T, key []byte, lines []string) ([]byte, []int) {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ends []int
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		ends = append(ends, buf.Len())
+	}
+	return buf.Bytes(), ends
+}
+
+// readAll 读到 EOF，返回明文与途中遇到的错误
+func readAll(t *testing.T, data, key []byte) (string, []error) {
+	t.Helper()
+	r, err := NewReader(bytes.NewReader(data), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	var errs []error
+	p := make([]byte, 7) // 小缓冲，覆盖一块分多次读取
+	for i := 0; i < 10000; i++ {
+		n, err := r.Read(p)
+		out.Write(p[:n])
+		if err == io.EOF {
+			return out.String(), errs
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.Fatal("reader did not reach EOF")
+	return "", nil
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriterSize(&buf, testKey, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	for i := 0; i < 100; i++ {
+		line := fmt.Sprintf("line %03d some log message\n", i)
+		want.WriteString(line)
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(buf.Bytes()) {
+		t.Fatal("output does not start with chunk magic")
+	}
+	if bytes.Contains(buf.Bytes(), []byte("some log message")) {
+		t.Fatal("plaintext visible in output")
+	}
+	got, errs := readAll(t, buf.Bytes(), testKey)
+	if len(errs) > 0 || got != want.String() {
+		t.Fatalf("round trip mismatch, errs=%v", errs)
+	}
+}
+
+// 多次启动追加写入同一文件：多段流首尾相接
+func TestStreamConcatenated(t *testing.T) {
+	a, _ := writeChunks(t, testKey, []string{"a1\n", "a2\n"})
+	b, _ := writeChunks(t, testKey, []string{"b1\n"})
+	got, errs := readAll(t, append(append([]byte(nil), a...), b...), testKey)
+	if len(errs) > 0 || got != "a1\na2\nb1\n" {
+		t.Fatalf("got %q, errs=%v", got, errs)
+	}
+}
+
+func TestStreamEmptyKey(t *testing.T) {
+	if _, err := NewWriter(io.Discard, nil); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("NewWriter(nil key) err = %v", err)
+	}
+	if _, err := NewReader(bytes.NewReader(nil), []byte{}); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("NewReader(empty key) err = %v", err)
+	}
+}
+
+func TestStreamWrongKey(t *testing.T) {
+	data, _ := writeChunks(t, testKey, []string{"secret\n"})
+	got, errs := readAll(t, data, bytes.Repeat([]byte{0x43}, 32))
+	if got != "" || len(errs) == 0 || !errors.Is(errs[0], ErrCorrupt) {
+		t.Fatalf("got %q, errs=%v", got, errs)
+	}
+}
+
+// 篡改的块报告 ErrCorrupt，之后的块仍可读取
+func TestStreamTamper(t *testing.T) {
+	data, ends := writeChunks(t, testKey, []string{"one\n", "two\n", "three\n"})
+	for _, off := range []int{ends[0] + 14, ends[0] + chunkHeaderSize + 1, ends[1] - 1} { // 序号、密文、tag
+		tampered := append([]byte(nil), data...)
+		tampered[off] ^= 0x01
+		got, errs := readAll(t, tampered, testKey)
+		if got != "one\nthree\n" {
+			t.Errorf("offset %d: got %q", off, got)
+		}
+		if len(errs) == 0 || !errors.Is(errs[0], ErrCorrupt) {
+			t.Errorf("offset %d: errs=%v, want ErrCorrupt", off, errs)
+		}
+	}
+}
+
+// 删除或调换块报告 ErrChunkGap
+func TestStreamGap(t *testing.T) {
+	data, ends := writeChunks(t, testKey, []string{"one\n", "two\n", "three\n"})
+	c0, c1, c2 := data[:ends[0]], data[ends[0]:ends[1]], data[ends[1]:ends[2]]
+
+	dropped := append(append([]byte(nil), c0...), c2...)
+	got, errs := readAll(t, dropped, testKey)
+	if got != "one\nthree\n" || len(errs) != 1 || !errors.Is(errs[0], ErrChunkGap) {
+		t.Fatalf("dropped chunk: got %q, errs=%v", got, errs)
+	}
+
+	swapped := append(append(append([]byte(nil), c0...), c2...), c1...)
+	_, errs = readAll(t, swapped, testKey)
+	if len(errs) == 0 || !errors.Is(errs[0], ErrChunkGap) {
+		t.Fatalf("reordered chunks: errs=%v, want ErrChunkGap", errs)
+	}
+}
+
+// 截断在块中间：已完整的块可读，残缺的块报告 ErrCorrupt
+func TestStreamTruncated(t *testing.T) {
+	data, ends := writeChunks(t, testKey, []string{"one\n", "two\n"})
+	got, errs := readAll(t, data[:ends[1]-5], testKey)
+	if got != "one\n" || len(errs) != 1 || !errors.Is(errs[0], ErrCorrupt) {
+		t.Fatalf("got %q, errs=%v", got, errs)
+	}
+}
@@ -3,21 +3,63 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"utils/config"
+	"utils/logger"
 )
 
+// Settings 应用自定义的配置，嵌入 config.Config 复用通用段
+type Settings struct {
+	config.Config
+	Kafka struct {
+		Brokers []string      `toml:"brokers" validate:"required"` // APP_KAFKA_BROKERS=a:9092,b:9092
+		Topic   string        `toml:"topic" default:"logs"`
+		Timeout time.Duration `toml:"timeout" default:"3s" validate:"min=100ms,max=1m"`
+	} `toml:"kafka"`
+}
+
 func main() {
-	// 加载多个配置文件
+	// 加载多个配置文件，环境变量优先，如 APP_DATABASE_PASSWORD=xxx、APP_LOG_MODULES="xmysql=debug,xredis=warn"
 	_, err := config.LoadConfig("config1.toml", "config2.toml")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	cfg := config.GetConfig()
+	cfg := config.MustGetConfig() // 或 cfg, err := config.GetConfig()
 
 	fmt.Println("AppName:", cfg.App.Name)
 	fmt.Println("AppPort:", cfg.App.Port)
 	fmt.Println("Database Host:", cfg.Database.Host)
 	fmt.Println("Redis Addr:", cfg.Redis.Addr)
+
+	// 按 [log] 段设置全局与各模块的日志级别
+	if err := logger.ApplyConfig(logger.Log, cfg.Log); err != nil {
+		log.Println("日志级别配置有误:", err)
+	}
+	logger.Named("xmysql").Debug(nil, "xmysql 模块的 debug 日志")
+
+	// 热加载：修改配置文件后自动重新读取，校验失败的修改不会生效；日志级别随配置调整
+	config.OnChange(func(old, new *config.Config) {
+		if err := logger.ApplyConfig(logger.Log, new.Log); err != nil {
+			log.Println("日志级别配置有误:", err)
+		}
+	})
+	stopWatch, err := config.Watch(config.WatchOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer stopWatch()
+
+	// 自定义结构体，每次调用返回新的实例，不影响全局配置；
+	// 未填写的字段取 default tag，validate tag 不满足时一次列出所有有误的键
+	settings, err := config.Load[Settings](
+		config.File("config1.toml"),
+		config.OptionalFile("config.local.toml"),
+		config.Env("APP"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Kafka Brokers:", settings.Kafka.Brokers)
 }
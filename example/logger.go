@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"os"
 	"time"
 
+	"utils/crpyto/aes"
 	"utils/logger"
 )
 
@@ -20,12 +23,55 @@ func main() {
 		)
 	}
 
-	log = logger.NewLogger("./app.log", 10, 5, 30, true, textFormatter)
+	// 同时输出到滚动文件和带颜色的 stderr（stderr 只输出 WARN 及以上）
+	log = logger.NewLogger("./app.log", 10, 5, 30, true, textFormatter,
+		logger.WithSink(logger.NewConsoleSink(true), logger.WarnLevel, nil),
+	)
 
 	defer log.(*logger.Logger).Close() // Close 需要具体类型才能调用
 
+	// SIGHUP 重新打开日志文件（外部 logrotate 移走文件后），SIGTERM/SIGINT 写完日志并关闭 logger。
+	// 本示例没有自己的信号处理，由 Exit 在关闭后退出进程；有优雅退出逻辑的应用保持默认即可
+	stopSignals := logger.HandleSignals(log, logger.SignalConfig{CloseTimeout: 3 * time.Second, Exit: true})
+	defer stopSignals()
+
 	log.Info(nil,"启动应用成功%s", "v1.0.0")
 	log.Warn(nil,"内存占用过高")
 	ctx := context.WithValue(context.Background(), logger.TraceIDKey, "abc123")
 	log.Error(ctx,"数据库连接失败")
+
+	// 结构化字段：With 派生子 logger，调用时也可以直接传 Field
+	dbLog := log.With(logger.F("module", "db"))
+	dbLog.Error(ctx, "查询失败", logger.F("table", "users"), logger.Err(fmt.Errorf("timeout")))
+
+	// 切换为 JSON Lines 输出
+	log.SetEntryFormatter(logger.JSONFormatter)
+	dbLog.Info(ctx, "连接池状态", logger.F("idle", 3), logger.F("inUse", 7))
+
+	// 数据库宕机时重试日志刷屏：10s 内相同的日志合并为一条 "(repeated N times in 10s)"，
+	// 同一格式串每秒前 10 条全部输出，之后每 100 条输出 1 条
+	quiet := logger.NewLogger("./quiet.log", 10, 5, 30, true, nil,
+		logger.WithDedup(10*time.Second),
+		logger.WithSampling(time.Second, 10, 100),
+	)
+	defer quiet.Close()
+	for i := 0; i < 5000; i++ {
+		quiet.Error(ctx, "连接 MySQL 失败", logger.Err(fmt.Errorf("connection refused")))
+	}
+
+	// 审计日志加密存储，读取：go run ./cmd/logq -key $LOG_ENCRYPT_KEY -backups ./audit.log。
+	// 密钥由 aes.GenerateKey 生成一次后保存在密钥管理系统中，通过环境变量下发，不要写在代码里
+	key, err := aes.ParseKey(os.Getenv("LOG_ENCRYPT_KEY"))
+	if err != nil {
+		fmt.Println("LOG_ENCRYPT_KEY 无效:", err)
+		return
+	}
+	audit := logger.NewLogger("./audit.log", 10, 30, 180, true, nil, logger.WithEncryption(key))
+	defer audit.Close()
+	audit.Info(ctx, "管理员修改权限", logger.F("operator", "admin"), logger.F("target", "user:1001"))
+
+	// 运行统计：按级别/模块的条数、队列深度、丢弃与写入失败次数，也可以 Prometheus 格式暴露
+	st := log.Stats()
+	fmt.Println("error:", st.Levels[logger.ErrorLevel], "queue:", st.QueueLen, "dropped:", st.Dropped, "write errors:", st.WriteErrors)
+	http.Handle("/metrics/logger", logger.MetricsHandler(log))
 }
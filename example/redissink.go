@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"utils/db/xredis"
+	"utils/logger"
+	"utils/logger/redissink"
+)
+
+func main() {
+	ctx := context.Background()
+
+	rdb, err := xredis.Open(ctx, xredis.Config{Addr: "127.0.0.1:6379"})
+	if err != nil {
+		log.Fatalf("failed to open redis: %v", err)
+	}
+	defer rdb.Close()
+
+	// 本地写文件，同时把 INFO 及以上推送到 Redis Stream，Stream 最多保留约 10 万条
+	sink := redissink.New(rdb, redissink.Config{Stream: "logs:order-service", MaxLen: 100000})
+	l := logger.NewLogger("./logs/app.log", 50, 5, 7, true, nil,
+		logger.WithSink(sink, logger.InfoLevel, nil),
+	)
+	defer l.Close() // 在 rdb.Close 之前执行，尽量推送剩余日志
+
+	l.Info(ctx, "订单创建", logger.F("order_id", 1001))
+	l.Error(ctx, "支付回调验签失败", logger.F("order_id", 1001))
+
+	// 收集端：XREADGROUP GROUP collector c1 STREAMS logs:order-service >
+}
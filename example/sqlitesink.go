@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"utils/db/xsqlite"
+	"utils/logger"
+	"utils/logger/sqlitesink"
+)
+
+func main() {
+	ctx := context.Background()
+
+	db, err := xsqlite.Open(ctx, xsqlite.Config{DBPath: "./logs.db"})
+	if err != nil {
+		log.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	// 日志写入 SQLite，保留 7 天
+	sink, err := sqlitesink.New(ctx, db, sqlitesink.Config{Retention: 7 * 24 * time.Hour})
+	if err != nil {
+		log.Fatalf("failed to create sink: %v", err)
+	}
+	l := logger.NewLogger("", 0, 0, 0, false, nil, logger.WithSink(sink, logger.InfoLevel, nil))
+
+	l.Info(ctx, "设备上线", logger.F("device", "edge-01"))
+	l.Warn(ctx, "温度过高", logger.F("temp", 81.5))
+	l.Error(ctx, "上报超时", logger.Err(fmt.Errorf("dial tcp: timeout")))
+	l.Close() // 写入剩余日志，必须在 db.Close 之前
+
+	// 查询最近一小时 WARN 及以上且包含 timeout 的日志
+	records, err := sink.Query(ctx, sqlitesink.Query{
+		Since:    time.Now().Add(-time.Hour),
+		MinLevel: logger.WarnLevel,
+		Contains: "timeout",
+	})
+	if err != nil {
+		log.Fatalf("query failed: %v", err)
+	}
+	for _, r := range records {
+		fmt.Println(r.Time.Format(time.RFC3339), r.Level, r.Message, r.Fields)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"utils/logger"
+	"utils/tracing"
+)
+
+func main() {
+	// 模拟上游请求带来的 traceparent
+	in := http.Header{}
+	in.Set(tracing.Header, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	sc, err := tracing.Extract(in)
+	if err != nil {
+		sc = tracing.New() // 没有上游 trace 时新开一条
+	} else {
+		sc = sc.Child() // 本服务作为新的 span
+	}
+	ctx := tracing.NewContext(context.Background(), sc)
+
+	// 日志自动带上 traceID 与 spanID
+	logger.Log.Info(ctx, "处理请求")
+
+	// 调用下游时透传
+	out := http.Header{}
+	tracing.Inject(out, sc)
+	fmt.Println("traceparent:", out.Get(tracing.Header))
+}
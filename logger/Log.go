@@ -3,20 +3,316 @@ package logger
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"utils/crpyto/aes"
+)
+
+// Log 全局默认 logger，首次使用时才创建（不会在 import 时创建日志文件）。
+// 路径、级别、格式取自 Init 的参数，未调用 Init 时取自环境变量：
+//
+//	LOG_PATH         日志文件路径，默认 ./logs/app.log，设为 "stderr" 则只输出到标准错误
+//	LOG_LEVEL        debug/info/warn/error，默认 info
+//	LOG_FORMAT       text/json，默认 text
+//	LOG_ENCRYPT_KEY  十六进制 AES 密钥，设置后日志文件加密写入
+var Log LoggerInterface = &lazyLogger{}
+
+// Config 全局 Log 的配置，零值字段依次取环境变量与默认值
+type Config struct {
+	Path       string
+	Level      string
+	Format     string
+	MaxSize    int // MB，默认 50
+	MaxBackups int
+	MaxAge     int    // 天
+	NoCompress bool   // 默认 gzip 历史文件
+	EncryptKey string // 十六进制 AES 密钥，非空时日志文件加密写入，见 WithEncryption
+}
+
+const (
+	envPath   = "LOG_PATH"
+	envLevel  = "LOG_LEVEL"
+	envFormat = "LOG_FORMAT"
+	envKey    = "LOG_ENCRYPT_KEY"
 )
 
-var Log LoggerInterface
+// 全局 Log 的当前实例，Init 每次替换都会递增 gen
+var defaultLog struct {
+	mu  sync.Mutex
+	cur atomic.Pointer[lazyState]
+}
+
+type lazyState struct {
+	gen uint64
+	l   LoggerInterface
+}
+
+// Init 按 cfg 创建全局 Log，opts 追加到默认选项之后。
+// 日志文件无法打开时退化为输出到 stderr；已存在的全局 Log 会在替换后关闭
+func Init(cfg Config, opts ...Option) error {
+	l, err := newDefaultLogger(cfg, opts...)
+	if err != nil {
+		return err
+	}
+	defaultLog.mu.Lock()
+	old := defaultLog.cur.Load()
+	var gen uint64
+	if old != nil {
+		gen = old.gen + 1
+	}
+	defaultLog.cur.Store(&lazyState{gen: gen, l: l})
+	defaultLog.mu.Unlock()
+
+	if old != nil {
+		old.l.Close()
+	}
+	return nil
+}
+
+// Named 返回全局 Log 的模块子 logger
+func Named(name string) LoggerInterface {
+	return Log.Named(name)
+}
+
+func loadDefault() *lazyState {
+	if s := defaultLog.cur.Load(); s != nil {
+		return s
+	}
+	defaultLog.mu.Lock()
+	defer defaultLog.mu.Unlock()
+	if s := defaultLog.cur.Load(); s != nil {
+		return s
+	}
+	l, err := newDefaultLogger(Config{})
+	if err != nil {
+		// 环境变量有误时仍然要能输出日志：只写 stderr，不读取环境变量，
+		// 密钥有误时也不能退化为明文写入文件
+		fmt.Fprintf(os.Stderr, "logger init error: %v, log to stderr only\n", err)
+		l = fallbackLogger()
+	}
+	s := &lazyState{l: l}
+	defaultLog.cur.Store(s)
+	return s
+}
+
+// 默认文本格式
+func textFormatter(level, msg string, t time.Time) string {
+	return fmt.Sprintf("[%s] [%s] %s\n",
+		t.Format("2006-01-02 15:04:05"),
+		level,
+		msg,
+	)
+}
+
+func newDefaultLogger(cfg Config, opts ...Option) (LoggerInterface, error) {
+	if cfg.Path == "" {
+		cfg.Path = os.Getenv(envPath)
+	}
+	if cfg.Path == "" {
+		cfg.Path = "./logs/app.log"
+	}
+	if cfg.Level == "" {
+		cfg.Level = os.Getenv(envLevel)
+	}
+	if cfg.Format == "" {
+		cfg.Format = os.Getenv(envFormat)
+	}
+	if cfg.EncryptKey == "" {
+		cfg.EncryptKey = os.Getenv(envKey)
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 50
+	}
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	var formatter Formatter
+	var entryFormatter EntryFormatter
+	switch strings.ToLower(cfg.Format) {
+	case "", "text":
+		formatter = textFormatter
+	case "json":
+		entryFormatter = JSONFormatter
+	default:
+		return nil, fmt.Errorf("未知的日志格式: %q", cfg.Format)
+	}
+
+	base := []Option{WithLevel(level)}
+	if cfg.EncryptKey != "" {
+		key, err := aes.ParseKey(cfg.EncryptKey)
+		if err != nil {
+			return nil, err
+		}
+		base = append(base, WithEncryption(key))
+	}
+	filename := cfg.Path
+	if filename == "stderr" || filename == "-" {
+		filename = ""
+	} else if err := checkWritable(filename); err != nil {
+		fmt.Fprintf(os.Stderr, "logger open %s error: %v, fallback to stderr\n", filename, err)
+		filename = ""
+	}
+	if filename == "" {
+		base = append(base, WithSink(NewConsoleSink(false), DebugLevel, nil))
+	}
+	// 经由 Log 代理调用多出一层栈帧
+	opts = append(append(base, opts...), addCallerSkip(1))
+
+	l := NewLogger(filename, cfg.MaxSize, cfg.MaxBackups, cfg.MaxAge, !cfg.NoCompress, formatter, opts...)
+	if entryFormatter != nil {
+		l.SetEntryFormatter(entryFormatter)
+	}
+	return l, nil
+}
+
+// fallbackLogger 只输出到 stderr 的 INFO 级文本 logger，不依赖任何配置，不会失败
+func fallbackLogger() LoggerInterface {
+	return NewLogger("", 0, 0, 0, false, textFormatter,
+		WithLevel(InfoLevel),
+		WithSink(NewConsoleSink(false), DebugLevel, nil),
+		addCallerSkip(1),
+	)
+}
+
+// checkWritable 确认日志文件可以创建并追加写入
+func checkWritable(filename string) error {
+	if hasTimeTokens(filename) {
+		filename = expandPattern(filename, time.Now())
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// =============================
+// 懒加载代理
+// =============================
+
+// lazyLogger 全局 Log 及其派生 logger 的代理，每次调用时解析到当前实例，
+// Init 替换实例后派生的 logger 会重新派生
+type lazyLogger struct {
+	derive func(LoggerInterface) LoggerInterface // 为 nil 表示全局 Log 本身
+	cache  atomic.Pointer[lazyState]
+}
+
+func (z *lazyLogger) resolve() LoggerInterface {
+	base := loadDefault()
+	if z.derive == nil {
+		return base.l
+	}
+	if c := z.cache.Load(); c != nil && c.gen == base.gen {
+		return c.l
+	}
+	l := z.derive(base.l)
+	z.cache.Store(&lazyState{gen: base.gen, l: l})
+	return l
+}
+
+func (z *lazyLogger) child(f func(LoggerInterface) LoggerInterface) LoggerInterface {
+	parent := z.derive
+	if parent == nil {
+		return &lazyLogger{derive: f}
+	}
+	return &lazyLogger{derive: func(l LoggerInterface) LoggerInterface { return f(parent(l)) }}
+}
+
+func (z *lazyLogger) Debug(ctx context.Context, format string, args ...interface{}) {
+	z.resolve().Debug(ctx, format, args...)
+}
+
+func (z *lazyLogger) Info(ctx context.Context, format string, args ...interface{}) {
+	z.resolve().Info(ctx, format, args...)
+}
+
+func (z *lazyLogger) Warn(ctx context.Context, format string, args ...interface{}) {
+	z.resolve().Warn(ctx, format, args...)
+}
+
+func (z *lazyLogger) Error(ctx context.Context, format string, args ...interface{}) {
+	z.resolve().Error(ctx, format, args...)
+}
+
+func (z *lazyLogger) Fatal(ctx context.Context, format string, args ...interface{}) {
+	z.resolve().Fatal(ctx, format, args...)
+}
 
-func init() {
-	textFormatter := func(level, msg string, t time.Time) string {
-		return fmt.Sprintf("[%s] [%s] %s\n",
-			t.Format("2006-01-02 15:04:05"),
-			level,
-			msg,
-		)
+func (z *lazyLogger) With(fields ...Field) LoggerInterface {
+	if len(fields) == 0 {
+		return z
 	}
+	return z.child(func(l LoggerInterface) LoggerInterface { return l.With(fields...) })
+}
+
+func (z *lazyLogger) Named(name string) LoggerInterface {
+	return z.child(func(l LoggerInterface) LoggerInterface { return l.Named(name) })
+}
+
+func (z *lazyLogger) SetLevel(level Level) { z.resolve().SetLevel(level) }
 
-	Log = NewLogger("./logs/app.log", 50, 0, 0, true, textFormatter)
-	Log.Info(context.TODO(), "Logger initialized successfully")
+func (z *lazyLogger) GetLevel() Level { return z.resolve().GetLevel() }
+
+func (z *lazyLogger) SetModuleLevel(name string, level Level) {
+	z.resolve().SetModuleLevel(name, level)
+}
+
+func (z *lazyLogger) ReplaceModuleLevels(levels map[string]Level) {
+	replaceModuleLevels(z.resolve(), levels)
+}
+
+// peek 返回已创建的实例，尚未创建时返回 nil，避免 Flush/Close 等调用触发创建
+func (z *lazyLogger) peek() LoggerInterface {
+	if defaultLog.cur.Load() == nil {
+		return nil
+	}
+	return z.resolve()
+}
+
+func (z *lazyLogger) Stats() Stats {
+	if l := z.peek(); l != nil {
+		return l.Stats()
+	}
+	return Stats{}
+}
+
+func (z *lazyLogger) SetFormatter(f Formatter) { z.resolve().SetFormatter(f) }
+
+func (z *lazyLogger) SetEntryFormatter(f EntryFormatter) { z.resolve().SetEntryFormatter(f) }
+
+func (z *lazyLogger) Flush() {
+	if l := z.peek(); l != nil {
+		l.Flush()
+	}
+}
+
+func (z *lazyLogger) Close() {
+	if l := z.peek(); l != nil {
+		l.Close()
+	}
+}
+
+// Reopen 见 Logger.Reopen
+func (z *lazyLogger) Reopen() {
+	if r, ok := z.peek().(interface{ Reopen() }); ok {
+		r.Reopen()
+	}
+}
+
+// CloseContext 见 Logger.CloseContext
+func (z *lazyLogger) CloseContext(ctx context.Context) error {
+	l := z.peek()
+	if l == nil {
+		return nil
+	}
+	return closeContext(ctx, l)
 }
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// runtime.Callers、fillCaller、log、Info 等级别方法各占一层
+const callerSkipBase = 4
+
+// fillCaller 记录调用位置，level 达到 stackLevel 时同时记录调用栈
+func (c *core) fillCaller(e *Entry, skip int) {
+	var pcs [64]uintptr
+	n := runtime.Callers(callerSkipBase+c.callerSkip+skip, pcs[:])
+	c.fillFrames(e, pcs[:n])
+}
+
+// fillCallerPC 以 pc（如 slog.Record.PC）所在的调用帧作为调用位置
+func (c *core) fillCallerPC(e *Entry, pc uintptr) {
+	if pc == 0 {
+		return
+	}
+	var pcs [64]uintptr
+	n := runtime.Callers(2, pcs[:])
+	for i := 0; i < n; i++ {
+		if pcs[i] == pc {
+			c.fillFrames(e, pcs[i:n])
+			return
+		}
+	}
+	// 当前调用栈中找不到 pc（如异步调用），只记录调用位置
+	c.fillFrames(e, []uintptr{pc})
+}
+
+func (c *core) fillFrames(e *Entry, pcs []uintptr) {
+	if len(pcs) == 0 {
+		return
+	}
+	frames := runtime.CallersFrames(pcs)
+	f, more := frames.Next()
+	e.Caller = fmt.Sprintf("%s:%d", shortPath(f.File), f.Line)
+	e.Function = f.Function
+
+	if e.Level < c.stackLevel {
+		return
+	}
+	var b strings.Builder
+	for {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+		f, more = frames.Next()
+	}
+	e.Stack = b.String()
+}
+
+// shortPath 只保留文件所在目录和文件名，如 xmysql/xmsql.go
+func shortPath(file string) string {
+	idx := strings.LastIndexByte(file, '/')
+	if idx < 0 {
+		return file
+	}
+	if idx = strings.LastIndexByte(file[:idx], '/'); idx < 0 {
+		return file
+	}
+	return file[idx+1:]
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+
+	"utils/config"
+)
+
+// ApplyConfig 按 config.LogConfig 设置 l 的全局级别与各模块级别，配置中没有的模块恢复为沿用父模块或全局级别，
+// 因此可在 config.OnChange 中反复调用。级别名称有误的项会被跳过并在返回的错误中列出
+func ApplyConfig(l LoggerInterface, c config.LogConfig) error {
+	var errs []error
+	if c.Level != "" {
+		if lvl, err := ParseLevel(c.Level); err != nil {
+			errs = append(errs, fmt.Errorf("log.level: %w", err))
+		} else {
+			l.SetLevel(lvl)
+		}
+	}
+	levels := make(map[string]Level, len(c.Modules))
+	for name, s := range c.Modules {
+		lvl, err := ParseLevel(s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("log.modules.%s: %w", name, err))
+			continue
+		}
+		levels[name] = lvl
+	}
+	replaceModuleLevels(l, levels)
+	return errors.Join(errs...)
+}
+
+// moduleLevelReplacer 由 Logger 等实现，见 Logger.ReplaceModuleLevels
+type moduleLevelReplacer interface {
+	ReplaceModuleLevels(levels map[string]Level)
+}
+
+// replaceModuleLevels 不支持 ReplaceModuleLevels 的实现只能逐个设置，无法恢复已删除的模块
+func replaceModuleLevels(l LoggerInterface, levels map[string]Level) {
+	if r, ok := l.(moduleLevelReplacer); ok {
+		r.ReplaceModuleLevels(levels)
+		return
+	}
+	for name, lvl := range levels {
+		l.SetModuleLevel(name, lvl)
+	}
+}
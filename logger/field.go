@@ -0,0 +1,40 @@
+package logger
+
+import "fmt"
+
+// Field 结构化日志字段
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F 构造一个字段
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err 构造一个 key 为 "error" 的字段
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// SplitArgs 将参数中的 Field（及 []Field）分离出来，其余参数用于格式化 msg，
+// 供自行实现 LoggerInterface 时与 Logger 保持一致（如 loggertest）
+func SplitArgs(format string, args []interface{}) (string, []Field) {
+	var fields []Field
+	rest := args[:0:0]
+	for _, a := range args {
+		switch v := a.(type) {
+		case Field:
+			fields = append(fields, v)
+		case []Field:
+			fields = append(fields, v...)
+		default:
+			rest = append(rest, a)
+		}
+	}
+	if len(fields) == 0 {
+		return fmt.Sprintf(format, args...), nil
+	}
+	return fmt.Sprintf(format, rest...), fields
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry 一条结构化日志记录
+type Entry struct {
+	Level   Level
+	Time    time.Time
+	TraceID string
+	SpanID  string
+	Message string
+	Fields  []Field
+
+	// 以下字段仅在启用 WithCaller 时填充
+	Caller   string // 文件:行号
+	Function string
+	Stack    string
+
+	flush  *sync.WaitGroup // 非 nil 时为 Flush 标记，不是真正的日志
+	reopen bool            // Flush 标记附带重新打开文件
+}
+
+// EntryFormatter 基于结构化记录的格式化方法
+type EntryFormatter func(e *Entry) string
+
+// TextFormatter 默认的文本格式：时间 [级别] [traceID: xxx spanID: xxx] 消息 k=v ...
+func TextFormatter(e *Entry) string {
+	return defaultFormatter(e.Level.String(), textMessage(e), e.Time) + e.Stack
+}
+
+// FromFormatter 将旧式 Formatter 适配为 EntryFormatter，traceID、调用位置与字段拼接进 msg，
+// 调用栈追加在格式化结果之后
+func FromFormatter(f Formatter) EntryFormatter {
+	return func(e *Entry) string {
+		return f(e.Level.String(), textMessage(e), e.Time) + e.Stack
+	}
+}
+
+// textMessage 生成文本格式下的消息体
+func textMessage(e *Entry) string {
+	var b strings.Builder
+	switch {
+	case e.TraceID != "" && e.SpanID != "":
+		fmt.Fprintf(&b, "[%s: %s spanID: %s] ", TraceIDKey, e.TraceID, e.SpanID)
+	case e.TraceID != "":
+		fmt.Fprintf(&b, "[%s: %s] ", TraceIDKey, e.TraceID)
+	}
+	if e.Caller != "" {
+		fmt.Fprintf(&b, "[%s %s] ", e.Caller, shortFunc(e.Function))
+	}
+	b.WriteString(e.Message)
+	for _, f := range e.Fields {
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(textValue(f.Value))
+	}
+	return b.String()
+}
+
+// shortFunc 去掉函数名中的包路径，如 utils/db/xmysql.(*DB).Open -> xmysql.(*DB).Open
+func shortFunc(fn string) string {
+	if idx := strings.LastIndexByte(fn, '/'); idx >= 0 {
+		return fn[idx+1:]
+	}
+	return fn
+}
+
+func textValue(v interface{}) string {
+	s := textValueRaw(v)
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// textValueRaw 将字段值转为文本，不加引号
+func textValueRaw(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case error:
+		return x.Error()
+	case fmt.Stringer:
+		return x.String()
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// JSON 格式下的保留 key，字段与之重名时加上 "fields." 前缀
+var reservedKeys = map[string]bool{
+	"level":    true,
+	"time":     true,
+	"trace_id": true,
+	"span_id":  true,
+	"msg":      true,
+	"caller":   true,
+	"func":     true,
+	"stack":    true,
+}
+
+// JSONFormatter 输出 JSON Lines，每条日志一行
+func JSONFormatter(e *Entry) string {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	writeJSONKV(&b, "level", e.Level.String(), false)
+	writeJSONKV(&b, "time", e.Time.Format(time.RFC3339Nano), true)
+	if e.TraceID != "" {
+		writeJSONKV(&b, "trace_id", e.TraceID, true)
+	}
+	if e.SpanID != "" {
+		writeJSONKV(&b, "span_id", e.SpanID, true)
+	}
+	writeJSONKV(&b, "msg", e.Message, true)
+	if e.Caller != "" {
+		writeJSONKV(&b, "caller", e.Caller, true)
+		writeJSONKV(&b, "func", e.Function, true)
+	}
+	if e.Stack != "" {
+		writeJSONKV(&b, "stack", e.Stack, true)
+	}
+	for _, f := range e.Fields {
+		key := f.Key
+		if reservedKeys[key] {
+			key = "fields." + key
+		}
+		writeJSONKV(&b, key, f.Value, true)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func writeJSONKV(b *bytes.Buffer, key string, value interface{}, comma bool) {
+	if comma {
+		b.WriteByte(',')
+	}
+	k, _ := json.Marshal(key)
+	b.Write(k)
+	b.WriteByte(':')
+	b.Write(jsonValue(value))
+}
+
+func jsonValue(v interface{}) []byte {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return data
+}
@@ -6,11 +6,31 @@ import (
 )
 
 // LoggerInterface 定义日志接口
+//
+// args 中的 Field 会作为结构化字段记录，其余参数用于格式化 format
 type LoggerInterface interface {
+	Debug(ctx context.Context, format string, args ...interface{})
 	Info(ctx context.Context, format string, args ...interface{})
 	Warn(ctx context.Context, format string, args ...interface{})
 	Error(ctx context.Context, format string, args ...interface{})
+	// Fatal 写入日志并刷盘后调用 os.Exit(1)
+	Fatal(ctx context.Context, format string, args ...interface{})
+	// With 返回携带固定字段的子 logger，与父 logger 共用输出
+	With(fields ...Field) LoggerInterface
+	// Named 返回带 module 字段、可单独设置级别的模块子 logger
+	Named(name string) LoggerInterface
+	// SetLevel 运行时调整最低输出级别，对 With 派生的子 logger 同样生效；
+	// 在 Named 派生的 logger 上调用时只调整该模块（及未单独设置级别的子模块）的级别
+	SetLevel(level Level)
+	GetLevel() Level
+	// SetModuleLevel 设置指定模块（Named 的名称）的级别
+	SetModuleLevel(name string, level Level)
+	// Stats 返回丢弃、溢出等运行统计
+	Stats() Stats
 	SetFormatter(f Formatter)
+	SetEntryFormatter(f EntryFormatter)
+	// Flush 阻塞直到此前的日志全部写入文件，不关闭 logger
+	Flush()
 	Close()
 }
 
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Level 日志级别
+type Level int32
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	FatalLevel
+)
+
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	case FatalLevel:
+		return "FATAL"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int32(l))
+	}
+}
+
+// ParseLevel 解析级别名称（不区分大小写），如 "debug"、"WARN"
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
+		return DebugLevel, nil
+	case "INFO", "":
+		return InfoLevel, nil
+	case "WARN", "WARNING":
+		return WarnLevel, nil
+	case "ERROR":
+		return ErrorLevel, nil
+	case "FATAL":
+		return FatalLevel, nil
+	}
+	return InfoLevel, fmt.Errorf("未知的日志级别: %q", s)
+}
+
+// levelInherit 表示模块未单独设置级别，沿用 logger 的全局级别
+const levelInherit = math.MinInt32
+
+// moduleLevel 单个模块的级别，未设置时沿 parent（"a.b" 的 parent 为 "a"）向上查找，
+// 都未设置时使用 logger 的全局级别
+type moduleLevel struct {
+	level  atomic.Int32
+	parent *moduleLevel
+}
+
+// moduleLevels 按模块名保存的级别，Named 派生的 logger 持有其中的指针，判断级别只需沿父模块做几次原子读
+type moduleLevels struct {
+	mu sync.Mutex
+	m  map[string]*moduleLevel
+}
+
+func (r *moduleLevels) get(name string) *moduleLevel {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getLocked(name)
+}
+
+func (r *moduleLevels) getLocked(name string) *moduleLevel {
+	if r.m == nil {
+		r.m = make(map[string]*moduleLevel)
+	}
+	v, ok := r.m[name]
+	if !ok {
+		v = new(moduleLevel)
+		v.level.Store(levelInherit)
+		if i := strings.LastIndexByte(name, '.'); i > 0 {
+			v.parent = r.getLocked(name[:i])
+		}
+		r.m[name] = v
+	}
+	return v
+}
+
+func (r *moduleLevels) set(name string, level Level) {
+	r.get(name).level.Store(int32(level))
+}
+
+// replace 设置 levels 中各模块的级别，其余模块恢复为继承
+func (r *moduleLevels) replace(levels map[string]Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, v := range r.m {
+		if _, ok := levels[name]; !ok {
+			v.level.Store(levelInherit)
+		}
+	}
+	for name, level := range levels {
+		r.getLocked(name).level.Store(int32(level))
+	}
+}
+
+// effectiveLevel 返回模块级别，模块及其父模块都未设置时返回 global
+func effectiveLevel(module *moduleLevel, global *atomic.Int32) Level {
+	for m := module; m != nil; m = m.parent {
+		if v := m.level.Load(); v != levelInherit {
+			return Level(v)
+		}
+	}
+	return Level(global.Load())
+}
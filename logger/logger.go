@@ -3,22 +3,78 @@ package logger
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
+
+	"utils/tracing"
 )
 
 type ctxKey string
 
 const TraceIDKey  ctxKey = "traceID"
+
+// traceFromContext 优先读取 tracing.SpanContext，其次兼容 TraceIDKey 下的字符串，
+// 类型不符的值直接忽略
+func traceFromContext(ctx context.Context) (traceID, spanID string) {
+	if sc, ok := tracing.FromContext(ctx); ok {
+		return sc.TraceID.String(), sc.SpanID.String()
+	}
+	if v, ok := ctx.Value(TraceIDKey).(string); ok {
+		return v, ""
+	}
+	return "", ""
+}
+
+// Logger 通过 With、Named 派生的子 logger 共用同一个 core
 type Logger struct {
-	writer    *lumberjack.Logger
-	logChan   chan string
+	*core
+	fields []Field
+
+	module      string
+	moduleLevel *moduleLevel // 仅 Named 派生的 logger 非 nil
+	counts      *levelCounters // 仅 Named 派生的 logger 非 nil
+}
+
+type core struct {
+	logChan   chan *Entry
+	sinks     []*sinkWorker
 	wg        sync.WaitGroup
 	closeOnce sync.Once
-	formatter Formatter
+	formatter atomic.Value // EntryFormatter
+	level     atomic.Int32
+	modules   moduleLevels
+
+	policy  OverflowPolicy
+	spill   *spillBuffer
+	dropped atomic.Uint64
+	spilled atomic.Uint64
+
+	flushInterval time.Duration
+	closeMu       sync.RWMutex // 入队持读锁，Close 持写锁，避免向已关闭的 channel 发送
+	closed        atomic.Bool
+	closeDone     chan struct{}
+
+	addCaller  bool
+	callerSkip int
+	stackLevel Level
+
+	redactor *Redactor
+
+	dedup      *deduper
+	sampler    *sampler
+	suppressed atomic.Uint64
+	sampled    atomic.Uint64
+
+	levelCounts  levelCounters
+	moduleCounts moduleCounters
+	writeErrors  atomic.Uint64
+	metricsHook  func(level Level, module string)
 }
 
 // 默认格式化器
@@ -31,89 +87,332 @@ func defaultFormatter(level, msg string, t time.Time) string {
 }
 
 // NewLogger 返回 LoggerInterface
-func NewLogger(filename string, maxSize, maxBackups, maxAge int, compress bool, formatter Formatter) LoggerInterface {
+//
+// filename 非空时创建一个按大小滚动的文件 sink，WithSink 可追加更多输出目的地。
+// 使用 WithRotation 或在 filename 中写入 %Y %m %d %H 占位符时按时间滚动，
+// maxBackups、maxAge（天）用于清理历史文件，compress 为 true 时后台 gzip 历史文件
+func NewLogger(filename string, maxSize, maxBackups, maxAge int, compress bool, formatter Formatter, opts ...Option) LoggerInterface {
+	o := defaultOptions()
+	for _, f := range opts {
+		f(o)
+	}
+
+	c := &core{
+		logChan: make(chan *Entry, o.bufferSize),
+		policy:  o.policy,
+
+		closeDone: make(chan struct{}),
+
+		flushInterval: o.flushInterval,
+
+		addCaller:  o.addCaller,
+		callerSkip: o.callerSkip,
+		stackLevel: o.stackLevel,
+
+		redactor: o.redactor,
+		sampler:  o.sampling,
+
+		metricsHook: o.metricsHook,
+	}
 	if formatter == nil {
-		formatter = defaultFormatter
+		c.formatter.Store(EntryFormatter(TextFormatter))
+	} else {
+		c.formatter.Store(FromFormatter(formatter))
 	}
-	l := &Logger{
-		writer: &lumberjack.Logger{
-			Filename:   filename,
-			MaxSize:    maxSize,
-			MaxBackups: maxBackups,
-			MaxAge:     maxAge,
-			Compress:   compress,
-		},
-		logChan:   make(chan string, 1000),
-		formatter: formatter,
+	c.level.Store(int32(o.level))
+
+	if filename != "" {
+		var w io.WriteCloser
+		if o.rotation != RotateNone || hasTimeTokens(filename) {
+			w = NewRotatingFile(RotateConfig{
+				Filename:   filename,
+				Rotation:   o.rotation,
+				MaxSize:    maxSize,
+				MaxBackups: maxBackups,
+				MaxAge:     maxAge,
+				Compress:   compress,
+			})
+		} else {
+			w = &lumberjack.Logger{
+				Filename:   filename,
+				MaxSize:    maxSize,
+				MaxBackups: maxBackups,
+				MaxAge:     maxAge,
+				Compress:   compress,
+			}
+		}
+		if !o.encrypt {
+			c.sinks = append(c.sinks, newSinkWorker(c, newFileSink(w, o.writeBufferSize), DebugLevel, nil))
+		} else if fs, err := newEncryptedFileSink(w, o.writeBufferSize, o.encryptKey); err == nil {
+			c.sinks = append(c.sinks, newSinkWorker(c, fs, DebugLevel, nil))
+		} else {
+			// 不能退化为明文写入，宁可不写文件
+			fmt.Fprintf(os.Stderr, "logger encryption error: %v, file output disabled\n", err)
+			_ = w.Close()
+		}
+	}
+	for _, sc := range o.sinks {
+		c.sinks = append(c.sinks, newSinkWorker(c, sc.sink, sc.level, sc.formatter))
+	}
+
+	if c.policy == OverflowSpill {
+		path := o.spillPath
+		if path == "" {
+			path = filename + ".spill"
+			if filename == "" {
+				path = filepath.Join(os.TempDir(), fmt.Sprintf("logger-%d.spill", os.Getpid()))
+			}
+		}
+		sb, err := openSpillBuffer(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger open spill buffer error: %v, fallback to drop_newest\n", err)
+			c.policy = OverflowDropNewest
+		} else {
+			c.spill = sb
+		}
 	}
 
-	l.wg.Add(1)
-	go l.run()
+	c.wg.Add(1)
+	go c.run()
+	if o.dedupWindow > 0 {
+		c.dedup = newDeduper(o.dedupWindow)
+		go c.dedup.run(c.emitSummary)
+	}
 
-	return l
+	return &Logger{core: c}
 }
 
-func (l *Logger) run() {
-	defer l.wg.Done()
-	for msg := range l.logChan {
-		if _, err := l.writer.Write([]byte(msg)); err != nil {
-			fmt.Fprintf(os.Stderr, "logger write error: %v\n", err)
+func (c *core) run() {
+	defer c.wg.Done()
+	c.replaySpill()
+	for e := range c.logChan {
+		c.dispatch(e)
+		// 队列空闲时回放磁盘缓冲中的日志
+		if len(c.logChan) == 0 {
+			c.replaySpill()
 		}
 	}
+	c.replaySpill()
 }
 
-// 内部 log 方法，可以自动从 ctx 中获取 traceID
-func (l *Logger) log(ctx context.Context, level, msg string) {
-	traceID := ""
-	if ctx != nil {
-		if v := ctx.Value(TraceIDKey); v != nil {
-			traceID = v.(string)
+func (c *core) dispatch(e *Entry) {
+	if e.flush != nil {
+		// 先回放磁盘缓冲，保证 Flush 之前溢出的日志也被写入
+		c.replaySpill()
+		e.flush.Add(len(c.sinks))
+		for _, s := range c.sinks {
+			s.dispatch(e)
 		}
+		e.flush.Done()
+		return
 	}
-	if traceID != "" {
-		msg = fmt.Sprintf("[%s: %s] %s", TraceIDKey ,traceID, msg)
+	for _, s := range c.sinks {
+		s.dispatch(e)
 	}
+}
 
-	formatted := l.formatter(level, msg, time.Now())
-	select {
-	case l.logChan <- formatted:
-	default:
-		// 丢弃日志时，保证至少在 stderr 打出来
-		fmt.Fprintf(os.Stderr, "logger channel full, drop log: %s\n", msg)
+func (c *core) replaySpill() {
+	if c.spill == nil || !c.spill.hasPending() {
+		return
+	}
+	if err := c.spill.drain(c.dispatch); err != nil {
+		fmt.Fprintf(os.Stderr, "logger replay spill error: %v\n", err)
 	}
 }
 
-func (l *Logger) Info(ctx context.Context, format string, args ...interface{}) {
-	if ctx == nil {
-		ctx = context.Background()
+// 内部 log 方法，可以自动从 ctx 中获取 traceID
+func (l *Logger) log(ctx context.Context, level Level, format string, args []interface{}) {
+	if !l.enabled(level) {
+		return
+	}
+	msg, fields := SplitArgs(format, args)
+	e := l.newEntry(ctx, level, msg, fields)
+	if !l.admit(e, format) {
+		return
+	}
+	l.count(level)
+	if l.addCaller {
+		l.fillCaller(e, 0)
 	}
-	l.log(ctx, "INFO", fmt.Sprintf(format, args...))
+
+	l.enqueue(e)
 }
 
-func (l *Logger) Warn(ctx context.Context, format string, args ...interface{}) {
+// newEntry 构造日志记录，合并子 logger 的固定字段
+func (l *Logger) newEntry(ctx context.Context, level Level, msg string, fields []Field) *Entry {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	l.log(ctx, "WARN", fmt.Sprintf(format, args...))
+	traceID, spanID := traceFromContext(ctx)
+
+	if len(l.fields) > 0 {
+		fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	}
+	return &Entry{
+		Level:   level,
+		Time:    time.Now(),
+		TraceID: traceID,
+		SpanID:  spanID,
+		Message: msg,
+		Fields:  fields,
+	}
+}
+
+func (l *Logger) enabled(level Level) bool {
+	return level >= effectiveLevel(l.moduleLevel, &l.level)
+}
+
+func (l *Logger) Debug(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, DebugLevel, format, args)
+}
+
+func (l *Logger) Info(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, InfoLevel, format, args)
+}
+
+func (l *Logger) Warn(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, WarnLevel, format, args)
 }
 
 func (l *Logger) Error(ctx context.Context, format string, args ...interface{}) {
-	if ctx == nil {
-		ctx = context.Background()
+	l.log(ctx, ErrorLevel, format, args)
+}
+
+// Fatal 记录日志，关闭 logger 确保落盘后退出进程，关闭最多等待 fatalCloseTimeout
+func (l *Logger) Fatal(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, FatalLevel, format, args)
+	closeCtx, cancel := context.WithTimeout(context.Background(), fatalCloseTimeout)
+	_ = l.CloseContext(closeCtx)
+	cancel()
+	os.Exit(1)
+}
+
+const fatalCloseTimeout = 5 * time.Second
+
+// SetLevel 运行时调整最低输出级别，可在信号处理或管理接口中调用。
+// 对 Named 派生的 logger 只调整该模块的级别
+func (l *Logger) SetLevel(level Level) {
+	if l.moduleLevel != nil {
+		l.moduleLevel.level.Store(int32(level))
+		return
+	}
+	l.level.Store(int32(level))
+}
+
+// GetLevel 返回当前生效的最低输出级别
+func (l *Logger) GetLevel() Level {
+	return effectiveLevel(l.moduleLevel, &l.level)
+}
+
+// SetModuleLevel 设置指定模块的级别，对已创建和之后创建的同名 logger 均生效，
+// 未单独设置级别的子模块（如 xmysql.pool）沿用该级别
+func (l *Logger) SetModuleLevel(name string, level Level) {
+	l.modules.set(name, level)
+}
+
+// ReplaceModuleLevels 设置 levels 中各模块的级别，其余模块恢复为沿用父模块或全局级别，
+// 供 ApplyConfig 在配置热加载时使用
+func (l *Logger) ReplaceModuleLevels(levels map[string]Level) {
+	l.modules.replace(levels)
+}
+
+// With 返回携带固定字段的子 logger，子 logger 的 Close 会关闭整个 logger
+func (l *Logger) With(fields ...Field) LoggerInterface {
+	if len(fields) == 0 {
+		return l
+	}
+	merged := make([]Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return &Logger{core: l.core, fields: merged, module: l.module, moduleLevel: l.moduleLevel, counts: l.counts}
+}
+
+// Named 返回模块子 logger，日志带上 module 字段，级别可通过 SetModuleLevel 单独设置。
+// 在已命名的 logger 上再调用时模块名以 "." 连接，如 xmysql.pool
+func (l *Logger) Named(name string) LoggerInterface {
+	if l.module != "" {
+		name = l.module + "." + name
 	}
-	l.log(ctx, "ERROR", fmt.Sprintf(format, args...))
+	fields := make([]Field, 0, len(l.fields)+1)
+	for _, f := range l.fields {
+		if f.Key != "module" {
+			fields = append(fields, f)
+		}
+	}
+	fields = append(fields, Field{Key: "module", Value: name})
+	return &Logger{core: l.core, fields: fields, module: name, moduleLevel: l.modules.get(name), counts: l.moduleCounts.get(name)}
 }
 
+// SetFormatter 设置旧式格式化器，对所有子 logger 及未单独指定格式化器的 sink 生效
 func (l *Logger) SetFormatter(f Formatter) {
 	if f != nil {
-		l.formatter = f
+		l.formatter.Store(FromFormatter(f))
+	}
+}
+
+// SetEntryFormatter 设置结构化格式化器（如 JSONFormatter），作用范围同 SetFormatter
+func (l *Logger) SetEntryFormatter(f EntryFormatter) {
+	if f != nil {
+		l.formatter.Store(f)
 	}
 }
 
+// Flush 阻塞直到调用前已入队的日志全部写入各 sink 并刷新缓冲
+func (l *Logger) Flush() {
+	l.barrier(false)
+}
+
+// Reopen 写完调用前已入队的日志后重新打开日志文件，用于外部 logrotate 移走文件之后（通常在 SIGHUP 时调用）。
+// 只对实现了 Reopener 的 sink 生效
+func (l *Logger) Reopen() {
+	l.barrier(true)
+}
+
+// barrier 投递 Flush 标记并等待所有 sink 处理完
+func (l *Logger) barrier(reopen bool) {
+	var wg sync.WaitGroup
+	l.closeMu.RLock()
+	if l.closed.Load() {
+		l.closeMu.RUnlock()
+		return
+	}
+	wg.Add(1)
+	l.logChan <- &Entry{flush: &wg, reopen: reopen}
+	l.closeMu.RUnlock()
+	wg.Wait()
+}
+
+// Close 写完队列中的日志后关闭所有 sink，之后的日志会被丢弃
 func (l *Logger) Close() {
+	_ = l.CloseContext(context.Background())
+}
+
+// CloseContext 同 Close，但最多等待到 ctx 结束，避免磁盘缓慢时退出流程卡住。
+// 超时返回错误，关闭过程在后台继续，未写完的日志可能丢失
+func (l *Logger) CloseContext(ctx context.Context) error {
 	l.closeOnce.Do(func() {
-		close(l.logChan)
-		l.wg.Wait()
-		_ = l.writer.Close()
+		go func() {
+			defer close(l.closeDone)
+			if l.dedup != nil {
+				l.dedup.close(l.emitSummary)
+			}
+			l.closeMu.Lock()
+			l.closed.Store(true)
+			close(l.logChan)
+			l.closeMu.Unlock()
+			l.wg.Wait()
+			for _, s := range l.sinks {
+				s.close()
+			}
+			if l.spill != nil {
+				_ = l.spill.close()
+			}
+		}()
 	})
+	select {
+	case <-l.closeDone:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("logger close: %w", ctx.Err())
+	}
 }
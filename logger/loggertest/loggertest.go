@@ -0,0 +1,411 @@
+// Package loggertest 提供记录日志的内存 logger，用于单元测试中断言日志输出，无需读取磁盘文件。
+//
+// 使用示例：
+//
+//	func TestPay(t *testing.T) {
+//		rec := loggertest.New(t)
+//		svc := NewService(rec) // 接收 logger.LoggerInterface
+//		svc.Pay(ctx, 100)
+//
+//		rec.AssertContains(logger.ErrorLevel, `余额不足`)
+//		rec.AssertNone(logger.WarnLevel)
+//		rec.AssertField(logger.ErrorLevel, "order_id", 1001)
+//	}
+//
+// 测试失败时会通过 t.Logf 输出全部已记录的日志。
+package loggertest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"utils/logger"
+	"utils/tracing"
+)
+
+// TB testing.TB 中用到的方法，*testing.T 与 *testing.B 均满足
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Logf(format string, args ...interface{})
+	Failed() bool
+	Cleanup(func())
+}
+
+// Entry 一条记录的日志
+type Entry struct {
+	Time    time.Time
+	Level   logger.Level
+	Message string
+	Fields  []logger.Field // 含 With 的固定字段
+	Module  string         // Named 的名称
+	Ctx     context.Context
+}
+
+// Field 返回第一个名为 key 的字段值
+func (e Entry) Field(key string) (interface{}, bool) {
+	for _, f := range e.Fields {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Value 返回记录日志时 ctx 中 key 对应的值
+func (e Entry) Value(key interface{}) interface{} {
+	if e.Ctx == nil {
+		return nil
+	}
+	return e.Ctx.Value(key)
+}
+
+// TraceID 返回 ctx 中的 traceID（tracing.SpanContext 或 logger.TraceIDKey）
+func (e Entry) TraceID() string {
+	if e.Ctx == nil {
+		return ""
+	}
+	if sc, ok := tracing.FromContext(e.Ctx); ok {
+		return sc.TraceID.String()
+	}
+	id, _ := e.Ctx.Value(logger.TraceIDKey).(string)
+	return id
+}
+
+func (e Entry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] [%s]", e.Time.Format("15:04:05.000"), e.Level)
+	if e.Module != "" {
+		fmt.Fprintf(&b, " [%s]", e.Module)
+	}
+	if id := e.TraceID(); id != "" {
+		fmt.Fprintf(&b, " [traceID: %s]", id)
+	}
+	b.WriteString(" ")
+	b.WriteString(e.Message)
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+	return b.String()
+}
+
+// 同一 Recorder 派生出的子 logger 共享的状态
+type state struct {
+	t       TB
+	mu      sync.Mutex
+	entries []Entry
+	level   atomic.Int32
+	modules map[string]logger.Level
+}
+
+// Recorder 实现 logger.LoggerInterface，把日志记录在内存中。
+// 默认级别为 DEBUG，Fatal 只记录不退出，Flush/Close 不做任何事
+type Recorder struct {
+	*state
+	fields []logger.Field
+	module string
+}
+
+var _ logger.LoggerInterface = (*Recorder)(nil)
+
+// New 创建 Recorder，t 为 nil 时断言方法会 panic；
+// t 不为 nil 时测试失败后自动输出全部已记录的日志
+func New(t TB) *Recorder {
+	s := &state{t: t, modules: make(map[string]logger.Level)}
+	s.level.Store(int32(logger.DebugLevel))
+	if t != nil {
+		t.Cleanup(func() {
+			if t.Failed() {
+				t.Logf("captured logs:\n%s", s.dump())
+			}
+		})
+	}
+	return &Recorder{state: s}
+}
+
+func (r *Recorder) log(ctx context.Context, level logger.Level, format string, args []interface{}) {
+	if level < r.GetLevel() {
+		return
+	}
+	msg, fields := logger.SplitArgs(format, args)
+	if len(r.fields) > 0 {
+		fields = append(append([]logger.Field(nil), r.fields...), fields...)
+	}
+	r.mu.Lock()
+	r.entries = append(r.entries, Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Fields:  fields,
+		Module:  r.module,
+		Ctx:     ctx,
+	})
+	r.mu.Unlock()
+}
+
+func (r *Recorder) Debug(ctx context.Context, format string, args ...interface{}) {
+	r.log(ctx, logger.DebugLevel, format, args)
+}
+
+func (r *Recorder) Info(ctx context.Context, format string, args ...interface{}) {
+	r.log(ctx, logger.InfoLevel, format, args)
+}
+
+func (r *Recorder) Warn(ctx context.Context, format string, args ...interface{}) {
+	r.log(ctx, logger.WarnLevel, format, args)
+}
+
+func (r *Recorder) Error(ctx context.Context, format string, args ...interface{}) {
+	r.log(ctx, logger.ErrorLevel, format, args)
+}
+
+// Fatal 只记录，不退出进程
+func (r *Recorder) Fatal(ctx context.Context, format string, args ...interface{}) {
+	r.log(ctx, logger.FatalLevel, format, args)
+}
+
+func (r *Recorder) With(fields ...logger.Field) logger.LoggerInterface {
+	if len(fields) == 0 {
+		return r
+	}
+	c := *r
+	c.fields = append(append([]logger.Field(nil), r.fields...), fields...)
+	return &c
+}
+
+func (r *Recorder) Named(name string) logger.LoggerInterface {
+	if r.module != "" {
+		name = r.module + "." + name
+	}
+	c := *r
+	c.module = name
+	return &c
+}
+
+// SetLevel 对 Named 子 logger 设置模块级别，否则设置全局级别
+func (r *Recorder) SetLevel(level logger.Level) {
+	if r.module != "" {
+		r.SetModuleLevel(r.module, level)
+		return
+	}
+	r.level.Store(int32(level))
+}
+
+// GetLevel 返回生效级别：模块自身、最近的上级模块、全局级别依次查找
+func (r *Recorder) GetLevel() logger.Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := r.module; name != ""; {
+		if level, ok := r.modules[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return logger.Level(r.level.Load())
+}
+
+func (r *Recorder) SetModuleLevel(name string, level logger.Level) {
+	r.mu.Lock()
+	r.modules[name] = level
+	r.mu.Unlock()
+}
+
+// ReplaceModuleLevels 与 Logger 相同：设置 levels 中各模块的级别，其余模块恢复为沿用上级
+func (r *Recorder) ReplaceModuleLevels(levels map[string]logger.Level) {
+	r.mu.Lock()
+	r.modules = make(map[string]logger.Level, len(levels))
+	for name, level := range levels {
+		r.modules[name] = level
+	}
+	r.mu.Unlock()
+}
+
+func (r *Recorder) Stats() logger.Stats { return logger.Stats{} }
+
+func (r *Recorder) SetFormatter(logger.Formatter) {}
+
+func (r *Recorder) SetEntryFormatter(logger.EntryFormatter) {}
+
+func (r *Recorder) Flush() {}
+
+func (r *Recorder) Close() {}
+
+// =============================
+// 查询
+// =============================
+
+// Entries 返回全部已记录的日志（含所有子 logger）
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Filter 返回指定级别、消息匹配正则 pattern 的日志，pattern 为空表示不限消息
+func (r *Recorder) Filter(level logger.Level, pattern string) []Entry {
+	re, err := compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return r.filter(level, re)
+}
+
+func (r *Recorder) filter(level logger.Level, re *regexp.Regexp) []Entry {
+	var out []Entry
+	for _, e := range r.Entries() {
+		if e.Level == level && (re == nil || re.MatchString(e.Message)) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// Reset 清空已记录的日志
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// Dump 以文本形式返回全部已记录的日志，每行一条
+func (r *Recorder) Dump() string {
+	return r.dump()
+}
+
+func (s *state) dump() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) == 0 {
+		return "  (none)"
+	}
+	var b strings.Builder
+	for i, e := range s.entries {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("  ")
+		b.WriteString(e.String())
+	}
+	return b.String()
+}
+
+// =============================
+// 断言
+// =============================
+
+func (r *Recorder) mustT() TB {
+	if r.t == nil {
+		panic("loggertest: assertion requires a Recorder created with a non-nil TB")
+	}
+	r.t.Helper()
+	return r.t
+}
+
+func (r *Recorder) mustCompile(pattern string) *regexp.Regexp {
+	t := r.mustT()
+	t.Helper()
+	re, err := compile(pattern)
+	if err != nil {
+		t.Fatalf("loggertest: %v", err)
+	}
+	return re
+}
+
+// AssertContains 断言至少有一条 level 级别、消息匹配正则 pattern 的日志
+func (r *Recorder) AssertContains(level logger.Level, pattern string) bool {
+	t := r.mustT()
+	t.Helper()
+	if len(r.filter(level, r.mustCompile(pattern))) > 0 {
+		return true
+	}
+	t.Errorf("expected a %s log matching %q, got:\n%s", level, pattern, r.dump())
+	return false
+}
+
+// AssertNotContains 断言没有 level 级别、消息匹配正则 pattern 的日志
+func (r *Recorder) AssertNotContains(level logger.Level, pattern string) bool {
+	t := r.mustT()
+	t.Helper()
+	found := r.filter(level, r.mustCompile(pattern))
+	if len(found) == 0 {
+		return true
+	}
+	t.Errorf("expected no %s log matching %q, found %d:\n%s", level, pattern, len(found), dumpEntries(found))
+	return false
+}
+
+// AssertNone 断言没有 level 级别的日志
+func (r *Recorder) AssertNone(level logger.Level) bool {
+	t := r.mustT()
+	t.Helper()
+	found := r.filter(level, nil)
+	if len(found) == 0 {
+		return true
+	}
+	t.Errorf("expected no %s logs, found %d:\n%s", level, len(found), dumpEntries(found))
+	return false
+}
+
+// AssertCount 断言 level 级别的日志恰好有 n 条
+func (r *Recorder) AssertCount(level logger.Level, n int) bool {
+	t := r.mustT()
+	t.Helper()
+	if got := len(r.filter(level, nil)); got != n {
+		t.Errorf("expected %d %s logs, got %d:\n%s", n, level, got, r.dump())
+		return false
+	}
+	return true
+}
+
+// AssertField 断言至少有一条 level 级别的日志带有字段 key，且值与 value 相等（reflect.DeepEqual，
+// error 类型的字段按 Error() 文本与字符串比较）
+func (r *Recorder) AssertField(level logger.Level, key string, value interface{}) bool {
+	t := r.mustT()
+	t.Helper()
+	for _, e := range r.filter(level, nil) {
+		if v, ok := e.Field(key); ok && fieldEqual(v, value) {
+			return true
+		}
+	}
+	t.Errorf("expected a %s log with field %s=%v, got:\n%s", level, key, value, r.dump())
+	return false
+}
+
+func fieldEqual(got, want interface{}) bool {
+	if err, ok := got.(error); ok {
+		if s, ok := want.(string); ok {
+			return err.Error() == s
+		}
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+func dumpEntries(entries []Entry) string {
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = "  " + e.String()
+	}
+	return strings.Join(lines, "\n")
+}
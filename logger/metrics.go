@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// WritePrometheus 以 Prometheus 文本格式输出统计，指标名以 logger_ 开头：
+//
+//	logger_messages_total{level="error"}                    各级别日志条数
+//	logger_module_messages_total{module="db",level="error"} 各模块各级别日志条数
+//	logger_dropped_total、logger_spilled_total、logger_suppressed_total、
+//	logger_sampled_total、logger_write_errors_total         丢弃、溢出、合并、采样、写入失败计数
+//	logger_queue_length、logger_queue_capacity、logger_sink_queue_length 队列深度
+func (s Stats) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	writeHeader(bw, "logger_messages_total", "counter", "Number of log entries emitted, by level.")
+	for lv := DebugLevel; lv <= FatalLevel; lv++ {
+		fmt.Fprintf(bw, "logger_messages_total{level=%q} %d\n", strings.ToLower(lv.String()), s.Levels[lv])
+	}
+
+	if len(s.Modules) > 0 {
+		names := make([]string, 0, len(s.Modules))
+		for name := range s.Modules {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		writeHeader(bw, "logger_module_messages_total", "counter", "Number of log entries emitted, by module and level.")
+		for _, name := range names {
+			counts := s.Modules[name]
+			for lv := DebugLevel; lv <= FatalLevel; lv++ {
+				fmt.Fprintf(bw, "logger_module_messages_total{module=\"%s\",level=%q} %d\n",
+					escapeLabel(name), strings.ToLower(lv.String()), counts[lv])
+			}
+		}
+	}
+
+	counters := []struct {
+		name, help string
+		value      uint64
+	}{
+		{"logger_dropped_total", "Log entries dropped because a queue was full.", s.Dropped},
+		{"logger_spilled_total", "Log entries spilled to disk because the queue was full.", s.Spilled},
+		{"logger_suppressed_total", "Duplicate log entries collapsed by dedup.", s.Suppressed},
+		{"logger_sampled_total", "Log entries discarded by sampling.", s.Sampled},
+		{"logger_write_errors_total", "Sink write, flush or reopen errors.", s.WriteErrors},
+	}
+	for _, c := range counters {
+		writeHeader(bw, c.name, "counter", c.help)
+		fmt.Fprintf(bw, "%s %d\n", c.name, c.value)
+	}
+
+	gauges := []struct {
+		name, help string
+		value      int
+	}{
+		{"logger_queue_length", "Current length of the main log queue.", s.QueueLen},
+		{"logger_queue_capacity", "Capacity of the main log queue.", s.QueueCap},
+		{"logger_sink_queue_length", "Total length of all sink queues.", s.SinkQueueLen},
+	}
+	for _, g := range gauges {
+		writeHeader(bw, g.name, "gauge", g.help)
+		fmt.Fprintf(bw, "%s %d\n", g.name, g.value)
+	}
+	return bw.Flush()
+}
+
+func writeHeader(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// escapeLabel 按 Prometheus 文本格式转义标签值
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// MetricsHandler 返回以 Prometheus 文本格式输出 l.Stats() 的 http.Handler
+//
+//	http.Handle("/metrics/logger", logger.MetricsHandler(logger.Log))
+func MetricsHandler(l LoggerInterface) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = l.Stats().WritePrometheus(w)
+	})
+}
@@ -0,0 +1,157 @@
+package logger
+
+import "time"
+
+// Option NewLogger 的可选配置
+type Option func(*options)
+
+type options struct {
+	level      Level
+	sinks      []sinkConfig
+	bufferSize int
+	policy     OverflowPolicy
+	spillPath  string
+
+	flushInterval   time.Duration
+	writeBufferSize int
+
+	addCaller  bool
+	callerSkip int
+	stackLevel Level
+
+	redactor *Redactor
+
+	rotation Rotation
+
+	dedupWindow time.Duration
+	sampling    *sampler
+
+	encrypt    bool
+	encryptKey []byte
+
+	metricsHook func(level Level, module string)
+}
+
+type sinkConfig struct {
+	sink      Sink
+	level     Level
+	formatter EntryFormatter
+}
+
+func defaultOptions() *options {
+	return &options{
+		level:      InfoLevel,
+		bufferSize: 1000,
+		policy:     OverflowDropNewest,
+
+		flushInterval:   time.Second,
+		writeBufferSize: defaultWriteBufferSize,
+
+		stackLevel: ErrorLevel,
+	}
+}
+
+// WithLevel 设置最低输出级别，低于该级别的日志直接丢弃
+func WithLevel(level Level) Option {
+	return func(o *options) { o.level = level }
+}
+
+// WithSink 追加一个输出目的地，level 为该 sink 自己的最低级别，
+// formatter 为 nil 时跟随 logger 的格式化器
+func WithSink(s Sink, level Level, formatter EntryFormatter) Option {
+	return func(o *options) {
+		o.sinks = append(o.sinks, sinkConfig{sink: s, level: level, formatter: formatter})
+	}
+}
+
+// WithBufferSize 设置日志队列长度，默认 1000
+func WithBufferSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.bufferSize = n
+		}
+	}
+}
+
+// WithOverflowPolicy 设置队列写满时的处理策略，默认 OverflowDropNewest
+func WithOverflowPolicy(p OverflowPolicy) Option {
+	return func(o *options) { o.policy = p }
+}
+
+// WithSpillPath 设置 OverflowSpill 策略使用的缓冲文件，默认为日志文件名加 ".spill"
+func WithSpillPath(path string) Option {
+	return func(o *options) { o.spillPath = path }
+}
+
+// WithFlushInterval 设置带缓冲 sink 的定时刷新间隔，默认 1s，<=0 表示只在缓冲写满、Flush 或 Close 时刷新
+func WithFlushInterval(d time.Duration) Option {
+	return func(o *options) { o.flushInterval = d }
+}
+
+// WithWriteBufferSize 设置日志文件的写缓冲大小，默认 256KB，<=0 表示不缓冲
+func WithWriteBufferSize(n int) Option {
+	return func(o *options) { o.writeBufferSize = n }
+}
+
+// WithCaller 记录调用位置（文件:行号与函数名），ERROR 及以上级别同时记录调用栈。
+// skip 为额外跳过的调用层数，封装了 logger 的辅助函数可传 1 使调用位置指向其调用方
+func WithCaller(skip int) Option {
+	return func(o *options) {
+		o.addCaller = true
+		o.callerSkip = skip
+	}
+}
+
+// addCallerSkip 在 WithCaller 的基础上再跳过 n 层，供内部封装使用
+func addCallerSkip(n int) Option {
+	return func(o *options) { o.callerSkip += n }
+}
+
+// WithStacktraceLevel 设置记录调用栈的最低级别，默认 ErrorLevel，需配合 WithCaller 使用
+func WithStacktraceLevel(level Level) Option {
+	return func(o *options) { o.stackLevel = level }
+}
+
+// WithRedactor 在格式化之前对日志脱敏，通常传入 NewRedactor() 并按需追加规则
+func WithRedactor(r *Redactor) Option {
+	return func(o *options) { o.redactor = r }
+}
+
+// WithRotation 日志文件按天或按小时滚动，可与 maxSize 同时使用。
+// filename 中含 %Y %m %d %H 占位符时会自动按时间滚动，如 ./logs/app-%Y-%m-%d.log
+func WithRotation(r Rotation) Option {
+	return func(o *options) { o.rotation = r }
+}
+
+// WithDedup 合并重复日志：window 内级别、模块与内容都相同的日志只输出第一条，
+// 窗口结束时补一条带 repeated 字段的汇总，如 "连接失败 (repeated 4,312 times in 10s)"
+func WithDedup(window time.Duration) Option {
+	return func(o *options) { o.dedupWindow = window }
+}
+
+// WithSampling 按级别、模块与格式串（format 参数）采样：每个 tick 内前 first 条全部输出，
+// 之后每 thereafter 条输出 1 条，thereafter <= 0 表示超出 first 的全部丢弃。FATAL 不参与采样
+func WithSampling(tick time.Duration, first, thereafter int) Option {
+	return func(o *options) {
+		if tick <= 0 || first < 0 {
+			o.sampling = nil
+			return
+		}
+		o.sampling = &sampler{tick: int64(tick), first: uint64(first), thereafter: uint64(max(thereafter, 0))}
+	}
+}
+
+// WithEncryption 日志文件经 AES-GCM 分块认证加密后写入，只有持有密钥才能读取（见 crpyto/aes 与 cmd/logq -key）。
+// key 须为 16/24/32 字节（可用 aes.GenerateKey 生成），否则不写日志文件并输出错误。只作用于 filename 对应的文件 sink
+func WithEncryption(key []byte) Option {
+	return func(o *options) {
+		o.encrypt = true
+		o.encryptKey = key
+	}
+}
+
+// WithMetricsHook 每条日志通过级别过滤、合并与采样后调用 fn，module 为 Named 的名称（未命名时为空），
+// 可用于对接已有的监控库。fn 在调用日志方法的 goroutine 中同步执行，应尽量轻量
+func WithMetricsHook(fn func(level Level, module string)) Option {
+	return func(o *options) { o.metricsHook = fn }
+}
@@ -0,0 +1,258 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy 日志队列写满时的处理策略
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest 丢弃新日志，并在 stderr 打出（默认）
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowBlock 阻塞调用方直到队列有空位
+	OverflowBlock
+	// OverflowDropOldest 丢弃队列中最旧的一条，为新日志腾出位置
+	OverflowDropOldest
+	// OverflowSpill 写入磁盘缓冲文件，队列空闲时再回放（回放的日志可能乱序）
+	OverflowSpill
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowDropNewest:
+		return "drop_newest"
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	case OverflowSpill:
+		return "spill"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// enqueue 脱敏后按溢出策略将日志放入队列，脱敏在入队前完成，溢出到磁盘的内容同样不含敏感信息
+func (c *core) enqueue(e *Entry) {
+	if c.redactor != nil {
+		c.redactor.Redact(e)
+	}
+	c.closeMu.RLock()
+	defer c.closeMu.RUnlock()
+	if c.closed.Load() {
+		c.dropped.Add(1)
+		fmt.Fprintf(os.Stderr, "logger closed, drop log: %s\n", e.Message)
+		return
+	}
+	if e.Level >= FatalLevel || c.policy == OverflowBlock {
+		c.logChan <- e
+		return
+	}
+	select {
+	case c.logChan <- e:
+		return
+	default:
+	}
+
+	switch c.policy {
+	case OverflowDropOldest:
+		pushDropOldest(c.logChan, e, &c.dropped)
+		return
+	case OverflowSpill:
+		err := c.spill.write(e)
+		if err == nil {
+			c.spilled.Add(1)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "logger spill error: %v\n", err)
+	}
+	c.dropped.Add(1)
+	// 丢弃日志时，保证至少在 stderr 打出来
+	fmt.Fprintf(os.Stderr, "logger channel full, drop log: %s\n", e.Message)
+}
+
+// pushDropOldest 不断淘汰 ch 中最旧的日志直到 e 入队。
+// Flush/Reopen 标记不会被淘汰：取出后放回队尾（仍在调用 Flush 之前的日志之后），继续淘汰下一条；
+// 队列中全是标记时阻塞等待空位
+func pushDropOldest(ch chan *Entry, e *Entry, dropped *atomic.Uint64) {
+	markers := 0
+	for {
+		select {
+		case old := <-ch:
+			if old.flush != nil {
+				ch <- old
+				if markers++; markers >= cap(ch) {
+					ch <- e
+					return
+				}
+				continue
+			}
+			dropped.Add(1)
+		default:
+		}
+		select {
+		case ch <- e:
+			return
+		default:
+		}
+	}
+}
+
+// =============================
+// 磁盘溢出缓冲
+// =============================
+
+// spillBuffer 以 JSON Lines 形式暂存溢出的日志
+type spillBuffer struct {
+	mu      sync.Mutex
+	f       *os.File
+	pending bool
+}
+
+type spillRecord struct {
+	Level   Level        `json:"level"`
+	Time    time.Time    `json:"time"`
+	TraceID string       `json:"trace_id,omitempty"`
+	SpanID  string       `json:"span_id,omitempty"`
+	Message string       `json:"msg"`
+	Fields  []spillField `json:"fields,omitempty"`
+
+	Caller   string `json:"caller,omitempty"`
+	Function string `json:"func,omitempty"`
+	Stack    string `json:"stack,omitempty"`
+}
+
+type spillField struct {
+	Key   string          `json:"k"`
+	Value json.RawMessage `json:"v"`
+}
+
+// openSpillBuffer 打开缓冲文件，文件中遗留的日志（如进程崩溃前写入的）会在启动后回放
+func openSpillBuffer(path string) (*spillBuffer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &spillBuffer{f: f, pending: info.Size() > 0}, nil
+}
+
+func (b *spillBuffer) write(e *Entry) error {
+	rec := spillRecord{
+		Level:   e.Level,
+		Time:    e.Time,
+		TraceID: e.TraceID,
+		SpanID:  e.SpanID,
+		Message: e.Message,
+
+		Caller:   e.Caller,
+		Function: e.Function,
+		Stack:    e.Stack,
+	}
+	for _, f := range e.Fields {
+		rec.Fields = append(rec.Fields, spillField{Key: f.Key, Value: jsonValue(f.Value)})
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := b.f.Write(data); err != nil {
+		return err
+	}
+	b.pending = true
+	return nil
+}
+
+func (b *spillBuffer) hasPending() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending
+}
+
+// drain 读出全部暂存日志并清空文件
+func (b *spillBuffer) drain(fn func(e *Entry)) error {
+	b.mu.Lock()
+	if !b.pending {
+		b.mu.Unlock()
+		return nil
+	}
+	if _, err := b.f.Seek(0, io.SeekStart); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	data, err := io.ReadAll(b.f)
+	if err == nil {
+		err = b.f.Truncate(0)
+	}
+	b.pending = false
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), len(data)+1)
+	for sc.Scan() {
+		var rec spillRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			continue
+		}
+		e := &Entry{
+			Level:   rec.Level,
+			Time:    rec.Time,
+			TraceID: rec.TraceID,
+			SpanID:  rec.SpanID,
+			Message: rec.Message,
+
+			Caller:   rec.Caller,
+			Function: rec.Function,
+			Stack:    rec.Stack,
+		}
+		for _, f := range rec.Fields {
+			var v interface{}
+			dec := json.NewDecoder(bytes.NewReader(f.Value))
+			dec.UseNumber()
+			if err := dec.Decode(&v); err != nil {
+				v = string(f.Value)
+			}
+			e.Fields = append(e.Fields, Field{Key: f.Key, Value: v})
+		}
+		fn(e)
+	}
+	return sc.Err()
+}
+
+// close 关闭并删除缓冲文件，调用前需已 drain
+func (b *spillBuffer) close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	name := b.f.Name()
+	if err := b.f.Close(); err != nil {
+		return err
+	}
+	if b.pending {
+		return nil
+	}
+	return os.Remove(name)
+}
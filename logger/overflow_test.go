@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// 队列满时淘汰最旧的日志，Flush/Reopen 标记保留
+func TestPushDropOldestKeepsMarkers(t *testing.T) {
+	ch := make(chan *Entry, 3)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	marker := &Entry{flush: &wg, reopen: true}
+	ch <- marker
+	ch <- &Entry{Message: "a"}
+	ch <- &Entry{Message: "b"}
+
+	var dropped atomic.Uint64
+	pushDropOldest(ch, &Entry{Message: "c"}, &dropped)
+
+	if dropped.Load() != 1 {
+		t.Fatalf("dropped = %d, want 1", dropped.Load())
+	}
+	// 标记放回队尾，仍在之前的日志 b 之后
+	var got []string
+	for len(ch) > 0 {
+		e := <-ch
+		if e == marker {
+			got = append(got, "<flush>")
+			continue
+		}
+		got = append(got, e.Message)
+	}
+	if strings.Join(got, ",") != "b,<flush>,c" {
+		t.Fatalf("queue = %v, want [b <flush> c]", got)
+	}
+}
+
+// 队列中全是标记时不会空转，等到有空位后入队
+func TestPushDropOldestAllMarkers(t *testing.T) {
+	ch := make(chan *Entry, 2)
+	var wg sync.WaitGroup
+	ch <- &Entry{flush: &wg}
+	ch <- &Entry{flush: &wg}
+
+	done := make(chan struct{})
+	var dropped atomic.Uint64
+	go func() {
+		pushDropOldest(ch, &Entry{Message: "c"}, &dropped)
+		close(done)
+	}()
+	for i := 0; i < 3; i++ {
+		if e := <-ch; i == 2 && e.Message != "c" {
+			t.Fatalf("third entry = %+v, want c", e)
+		}
+	}
+	<-done
+	if dropped.Load() != 0 {
+		t.Fatalf("dropped = %d, want 0", dropped.Load())
+	}
+}
@@ -0,0 +1,303 @@
+// Package redissink 将日志推送到 Redis Stream（通过 xredis.DB），供中心化的收集端消费多个实例的日志。
+//
+// 特性：
+//   - 后台按批次用 pipeline 执行 XADD，Write 只写本地缓冲，永不阻塞业务
+//   - 以 MAXLEN ~ 限制 Stream 长度
+//   - Redis 不可用时在本地有界缓冲中积压，按指数退避重试，缓冲满时丢弃最旧的日志并计数
+//
+// 使用示例：
+//
+//	rdb, _ := xredis.Open(ctx, xredis.Config{Addr: "127.0.0.1:6379"})
+//	sink := redissink.New(rdb, redissink.Config{Stream: "logs:app", MaxLen: 100000})
+//	l := logger.NewLogger("./logs/app.log", 50, 5, 7, true, nil,
+//		logger.WithSink(sink, logger.InfoLevel, nil))
+//	defer rdb.Close()
+//	defer l.Close() // 先关闭 logger（会尽量推送剩余日志），再关闭 rdb
+//
+// 每条记录的字段为 level、time、instance、msg，以及非空时的 trace_id、span_id、caller、fields（JSON）；
+// Formatted 为 true 时只写 instance 与 line（sink 格式化后的文本）。
+package redissink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"utils/db/xredis"
+	"utils/logger"
+)
+
+type Config struct {
+	Stream           string        // Stream 名，默认 logs
+	MaxLen           int64         // Stream 近似最大长度，默认 100000，负数表示不限
+	Instance         string        // 实例标识，默认主机名
+	Formatted        bool          // 只推送格式化后的文本
+	BatchSize        int           // 每个 pipeline 的最大条数，默认 100
+	BufferSize       int           // 本地缓冲条数，默认 10000
+	FlushInterval    time.Duration // 推送间隔，默认 500ms
+	MaxRetryInterval time.Duration // 失败后的最大重试间隔，默认 30s
+	Timeout          time.Duration // 单次推送超时，默认 3s
+}
+
+// Sink 实现 logger.Sink 与 logger.Flusher
+type Sink struct {
+	db  *xredis.DB
+	cfg Config
+
+	mu  sync.Mutex
+	buf []record
+	seq uint64
+
+	notify  chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+type record struct {
+	seq    uint64
+	values map[string]interface{}
+}
+
+// New 创建 sink 并启动后台推送，db 的生命周期由调用方管理
+func New(db *xredis.DB, cfg Config) *Sink {
+	if cfg.Stream == "" {
+		cfg.Stream = "logs"
+	}
+	if cfg.MaxLen == 0 {
+		cfg.MaxLen = 100000
+	}
+	if cfg.Instance == "" {
+		cfg.Instance, _ = os.Hostname()
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 500 * time.Millisecond
+	}
+	if cfg.MaxRetryInterval <= 0 {
+		cfg.MaxRetryInterval = 30 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+	s := &Sink{
+		db:     db,
+		cfg:    cfg,
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write 将日志放入本地缓冲，缓冲满时丢弃最旧的一条
+func (s *Sink) Write(e *logger.Entry, p []byte) error {
+	values := s.values(e, p)
+
+	s.mu.Lock()
+	if len(s.buf) >= s.cfg.BufferSize {
+		s.buf[0] = record{}
+		s.buf = s.buf[1:]
+		s.dropped.Add(1)
+	}
+	s.seq++
+	s.buf = append(s.buf, record{seq: s.seq, values: values})
+	full := len(s.buf) >= s.cfg.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		s.wake()
+	}
+	return nil
+}
+
+func (s *Sink) values(e *logger.Entry, p []byte) map[string]interface{} {
+	if s.cfg.Formatted {
+		return map[string]interface{}{
+			"instance": s.cfg.Instance,
+			"line":     strings.TrimRight(string(p), "\n"),
+		}
+	}
+	v := map[string]interface{}{
+		"level":    e.Level.String(),
+		"time":     e.Time.Format(time.RFC3339Nano),
+		"instance": s.cfg.Instance,
+		"msg":      e.Message,
+	}
+	if e.TraceID != "" {
+		v["trace_id"] = e.TraceID
+	}
+	if e.SpanID != "" {
+		v["span_id"] = e.SpanID
+	}
+	if e.Caller != "" {
+		v["caller"] = e.Caller
+	}
+	if len(e.Fields) > 0 {
+		m := make(map[string]interface{}, len(e.Fields))
+		for _, f := range e.Fields {
+			if err, ok := f.Value.(error); ok {
+				m[f.Key] = err.Error()
+			} else {
+				m[f.Key] = f.Value
+			}
+		}
+		if data, err := json.Marshal(m); err == nil {
+			v["fields"] = string(data)
+		}
+	}
+	return v
+}
+
+// Flush 通知后台立即推送，不等待结果
+func (s *Sink) Flush() error {
+	s.wake()
+	return nil
+}
+
+func (s *Sink) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Dropped 返回因本地缓冲满或关闭时仍未推送成功而丢弃的条数
+func (s *Sink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close 停止后台推送，并在 Timeout 内尽量推送剩余日志，不关闭 db
+func (s *Sink) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+
+	deadline := time.Now().Add(s.cfg.Timeout)
+	var err error
+	for s.pending() > 0 && time.Now().Before(deadline) {
+		if err = s.ship(); err != nil {
+			break
+		}
+	}
+	s.mu.Lock()
+	if n := len(s.buf); n > 0 {
+		s.dropped.Add(uint64(n))
+		s.buf = nil
+		fmt.Fprintf(os.Stderr, "redissink: %d log entries dropped on close\n", n)
+	}
+	s.mu.Unlock()
+	return err
+}
+
+func (s *Sink) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buf)
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+	timer := time.NewTimer(s.cfg.FlushInterval)
+	defer timer.Stop()
+
+	backoff := time.Duration(0)
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-s.notify:
+			if backoff > 0 {
+				// 失败退避期间忽略唤醒，等待定时器
+				continue
+			}
+		case <-timer.C:
+		}
+
+		var err error
+		for s.pending() > 0 {
+			if err = s.ship(); err != nil {
+				break
+			}
+		}
+
+		switch {
+		case err == nil:
+			if backoff > 0 {
+				fmt.Fprintf(os.Stderr, "redissink: stream %s recovered\n", s.cfg.Stream)
+			}
+			backoff = 0
+		case backoff == 0:
+			fmt.Fprintf(os.Stderr, "redissink: push to stream %s failed: %v, retrying\n", s.cfg.Stream, err)
+			backoff = s.cfg.FlushInterval
+		default:
+			backoff = min(backoff*2, s.cfg.MaxRetryInterval)
+		}
+
+		wait := s.cfg.FlushInterval
+		if backoff > 0 {
+			wait = backoff
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+	}
+}
+
+// ship 以一个 pipeline 推送缓冲头部的一批日志，成功后才从缓冲中移除
+func (s *Sink) ship() error {
+	s.mu.Lock()
+	batch := append([]record(nil), s.buf[:min(len(s.buf), s.cfg.BatchSize)]...)
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+	err := s.db.ExecSync(ctx, func(c redis.Cmdable) error {
+		pipe := c.Pipeline()
+		for _, r := range batch {
+			args := &redis.XAddArgs{Stream: s.cfg.Stream, Values: r.values}
+			if s.cfg.MaxLen > 0 {
+				args.MaxLen = s.cfg.MaxLen
+				args.Approx = true
+			}
+			pipe.XAdd(ctx, args)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// 推送期间缓冲满时头部可能已被丢弃，按序号移除已推送的部分
+	last := batch[len(batch)-1].seq
+	s.mu.Lock()
+	i := 0
+	for i < len(s.buf) && s.buf[i].seq <= last {
+		s.buf[i] = record{}
+		i++
+	}
+	s.buf = s.buf[i:]
+	s.mu.Unlock()
+	return nil
+}
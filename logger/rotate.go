@@ -0,0 +1,397 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation 按时间滚动的周期
+type Rotation int
+
+const (
+	// RotateNone 不按时间滚动（文件名中含 %H、%d 时会自动推断）
+	RotateNone Rotation = iota
+	// RotateDaily 每天零点滚动
+	RotateDaily
+	// RotateHourly 每小时滚动
+	RotateHourly
+)
+
+// RotateConfig 滚动文件配置
+type RotateConfig struct {
+	// Filename 文件名模式，支持 %Y %m %d %H（年月日时）与 %%，如 ./logs/app-%Y-%m-%d.log。
+	// 按时间滚动但未写占位符时，自动在扩展名前追加 -%Y-%m-%d 或 -%Y-%m-%d-%H
+	Filename string
+	Rotation Rotation
+	// MaxSize 单个文件最大 MB，超过后当前文件改名为 app-2026-10-16.1.log 并重新打开，0 表示不限
+	MaxSize int
+	// MaxBackups 保留的历史文件数，0 表示不限
+	MaxBackups int
+	// MaxAge 历史文件保留天数，0 表示不限
+	MaxAge int
+	// Compress 是否在后台 gzip 历史文件
+	Compress bool
+}
+
+// RotatingFile 支持按天/按小时与按大小滚动的日志文件，实现 io.WriteCloser
+type RotatingFile struct {
+	cfg     RotateConfig
+	pattern string
+
+	mu         sync.Mutex
+	file       *os.File
+	name       string
+	size       int64
+	nextRotate time.Time
+
+	millCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewRotatingFile 创建滚动文件，首次写入时才打开文件
+func NewRotatingFile(cfg RotateConfig) *RotatingFile {
+	if cfg.Rotation == RotateNone {
+		cfg.Rotation = inferRotation(cfg.Filename)
+	}
+	r := &RotatingFile{
+		cfg:     cfg,
+		pattern: rotatePattern(cfg.Filename, cfg.Rotation),
+		millCh:  make(chan struct{}, 1),
+	}
+	r.wg.Add(1)
+	go r.millRun(r.millCh)
+	return r
+}
+
+// hasTimeTokens 文件名中是否含有时间占位符
+func hasTimeTokens(name string) bool {
+	return strings.Contains(name, "%Y") || strings.Contains(name, "%m") ||
+		strings.Contains(name, "%d") || strings.Contains(name, "%H")
+}
+
+func inferRotation(name string) Rotation {
+	switch {
+	case strings.Contains(name, "%H"):
+		return RotateHourly
+	case strings.Contains(name, "%d"):
+		return RotateDaily
+	}
+	return RotateNone
+}
+
+func rotatePattern(name string, rotation Rotation) string {
+	if rotation == RotateNone || hasTimeTokens(name) {
+		return name
+	}
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if rotation == RotateHourly {
+		return stem + "-%Y-%m-%d-%H" + ext
+	}
+	return stem + "-%Y-%m-%d" + ext
+}
+
+// expandPattern 按时间展开文件名
+func expandPattern(pattern string, t time.Time) string {
+	return strings.NewReplacer(
+		"%%", "%",
+		"%Y", fmt.Sprintf("%04d", t.Year()),
+		"%m", fmt.Sprintf("%02d", int(t.Month())),
+		"%d", fmt.Sprintf("%02d", t.Day()),
+		"%H", fmt.Sprintf("%02d", t.Hour()),
+	).Replace(pattern)
+}
+
+func (r *RotatingFile) periodEnd(t time.Time) time.Time {
+	switch r.cfg.Rotation {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if r.file == nil || (!r.nextRotate.IsZero() && !now.Before(r.nextRotate)) {
+		if err := r.openLocked(now); err != nil {
+			return 0, err
+		}
+	}
+	if limit := int64(r.cfg.MaxSize) * 1024 * 1024; limit > 0 && r.size > 0 && r.size+int64(len(p)) > limit {
+		if err := r.rotateSizeLocked(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// openLocked 打开当前周期对应的文件，并在后台清理、压缩历史文件
+func (r *RotatingFile) openLocked(now time.Time) error {
+	name := expandPattern(r.pattern, now)
+	if r.file != nil {
+		if name == r.name {
+			r.nextRotate = r.periodEnd(now)
+			return nil
+		}
+		if err := r.file.Close(); err != nil {
+			return err
+		}
+		r.file = nil
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	r.file, r.name, r.size = f, name, info.Size()
+	r.nextRotate = r.periodEnd(now)
+	r.mill()
+	return nil
+}
+
+// rotateSizeLocked 当前文件超过大小限制，改名为带序号的备份后重新打开
+func (r *RotatingFile) rotateSizeLocked(now time.Time) error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+	ext := filepath.Ext(r.name)
+	stem := strings.TrimSuffix(r.name, ext)
+	backup := fmt.Sprintf("%s.%d%s", stem, nextBackupIndex(stem, ext), ext)
+	if err := os.Rename(r.name, backup); err != nil {
+		return err
+	}
+	return r.openLocked(now)
+}
+
+func nextBackupIndex(stem, ext string) int {
+	matches, _ := filepath.Glob(stem + ".*" + ext + "*")
+	idx := 0
+	for _, m := range matches {
+		rest := strings.TrimPrefix(m, stem+".")
+		rest = strings.TrimSuffix(strings.TrimSuffix(rest, ".gz"), ext)
+		if n, err := strconv.Atoi(rest); err == nil && n > idx {
+			idx = n
+		}
+	}
+	return idx + 1
+}
+
+// Rotate 立即关闭当前文件，下次写入时重新打开（可配合外部 logrotate 使用）
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	if r.millCh != nil {
+		close(r.millCh)
+		r.millCh = nil
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+	return err
+}
+
+// =============================
+// 后台压缩与清理
+// =============================
+
+func (r *RotatingFile) mill() {
+	select {
+	case r.millCh <- struct{}{}:
+	default:
+	}
+}
+
+// millRun 的 ch 由调用方传入：Close 会将 r.millCh 置为 nil，goroutine 启动前读取字段会永久阻塞
+func (r *RotatingFile) millRun(ch <-chan struct{}) {
+	defer r.wg.Done()
+	for range ch {
+		if err := r.millOnce(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger rotate cleanup error: %v\n", err)
+		}
+	}
+}
+
+type backupFile struct {
+	path    string
+	modTime time.Time
+}
+
+// backups 列出除当前文件外所有匹配模式的历史文件（含 .gz）。
+// glob 只用于初步筛选，再用 backupRegexp 精确匹配，避免误删 app-audit-*.log 等同目录下的其他文件
+func (r *RotatingFile) backups() ([]backupFile, error) {
+	glob := strings.NewReplacer("%%", "%", "%Y", "*", "%m", "*", "%d", "*", "%H", "*").Replace(r.pattern)
+	ext := filepath.Ext(glob)
+	stem := strings.TrimSuffix(glob, ext)
+	re := backupRegexp(r.pattern)
+
+	r.mu.Lock()
+	active := r.name
+	r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var files []backupFile
+	for _, g := range []string{stem + ext, stem + ".*" + ext, stem + ext + ".gz", stem + ".*" + ext + ".gz"} {
+		matches, err := filepath.Glob(g)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if m == active || seen[m] || !re.MatchString(m) {
+				continue
+			}
+			seen[m] = true
+			info, err := os.Stat(m)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			files = append(files, backupFile{path: m, modTime: info.ModTime()})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	return files, nil
+}
+
+// backupRegexp 由文件名模式生成匹配历史文件的正则：%Y 为 4 位数字，%m %d %H 为 2 位数字，
+// 扩展名前可以有按大小滚动的 .N 序号，末尾可以有 .gz
+func backupRegexp(pattern string) *regexp.Regexp {
+	ext := filepath.Ext(pattern)
+	stem := strings.TrimSuffix(pattern, ext)
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(stem); i++ {
+		if stem[i] == '%' && i+1 < len(stem) {
+			switch stem[i+1] {
+			case 'Y':
+				b.WriteString(`\d{4}`)
+				i++
+				continue
+			case 'm', 'd', 'H':
+				b.WriteString(`\d{2}`)
+				i++
+				continue
+			case '%':
+				b.WriteString("%")
+				i++
+				continue
+			}
+		}
+		b.WriteString(regexp.QuoteMeta(stem[i : i+1]))
+	}
+	b.WriteString(`(\.\d+)?`)
+	b.WriteString(regexp.QuoteMeta(ext))
+	b.WriteString(`(\.gz)?$`)
+	return regexp.MustCompile(b.String())
+}
+
+func (r *RotatingFile) millOnce() error {
+	files, err := r.backups()
+	if err != nil {
+		return err
+	}
+
+	var cutoff time.Time
+	if r.cfg.MaxAge > 0 {
+		cutoff = time.Now().Add(-time.Duration(r.cfg.MaxAge) * 24 * time.Hour)
+	}
+	var remaining []backupFile
+	for i, f := range files {
+		if (r.cfg.MaxBackups > 0 && i >= r.cfg.MaxBackups) || (!cutoff.IsZero() && f.modTime.Before(cutoff)) {
+			if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		remaining = append(remaining, f)
+	}
+
+	if !r.cfg.Compress {
+		return nil
+	}
+	for _, f := range remaining {
+		if strings.HasSuffix(f.path, ".gz") {
+			continue
+		}
+		if err := gzipFile(f.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gzipFile 压缩为 path.gz 并删除原文件，保留原文件的修改时间以便按时间清理
+func gzipFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dstPath := path + ".gz"
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(dstPath)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	_ = os.Chtimes(dstPath, info.ModTime(), info.ModTime())
+	return os.Remove(path)
+}
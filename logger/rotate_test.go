@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestBackupRegexp(t *testing.T) {
+	re := backupRegexp("/var/log/app-%Y-%m-%d.log")
+	for _, name := range []string{
+		"/var/log/app-2026-10-12.log",
+		"/var/log/app-2026-10-12.3.log",
+		"/var/log/app-2026-10-12.log.gz",
+		"/var/log/app-2026-10-12.3.log.gz",
+	} {
+		if !re.MatchString(name) {
+			t.Errorf("%s should match", name)
+		}
+	}
+	for _, name := range []string{
+		"/var/log/app-audit-2026-10-12.log",
+		"/var/log/app-2026-10-12-01.log",
+		"/var/log/app-2026-1-12.log",
+		"/var/log/app-2026-10-12.log.bak",
+		"/var/log/app-2026-10-12.x.log",
+	} {
+		if re.MatchString(name) {
+			t.Errorf("%s should not match", name)
+		}
+	}
+}
+
+// 同目录下其他组件的文件（app-audit-*.log）不能被清理或压缩
+func TestRotatingFileMillSkipsNeighbours(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-72 * time.Hour)
+	touch := func(name string, age time.Duration) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mt := old.Add(age)
+		if err := os.Chtimes(path, mt, mt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	neighbours := []string{"app-audit-2026-10-10.log", "app-audit-2026-10-11.log", "app-audit-2026-10-12.log"}
+	for i, name := range neighbours {
+		touch(name, time.Duration(i)*time.Hour)
+	}
+	touch("app-2026-10-10.log", 0)
+	touch("app-2026-10-11.log", time.Hour)
+	touch("app-2026-10-12.1.log", 2*time.Hour)
+	touch("app-2026-10-12.log", 3*time.Hour)
+
+	r := NewRotatingFile(RotateConfig{
+		Filename:   filepath.Join(dir, "app.log"),
+		Rotation:   RotateDaily,
+		MaxBackups: 1,
+		Compress:   true,
+	})
+	defer r.Close()
+	if err := r.millOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	sort.Strings(got)
+	want := append([]string{"app-2026-10-12.log.gz"}, neighbours...)
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("files = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// admit 依次应用重复合并与采样，返回 false 表示该日志不输出。FATAL 不受影响
+func (l *Logger) admit(e *Entry, template string) bool {
+	if e.Level >= FatalLevel {
+		return true
+	}
+	if l.dedup != nil {
+		ok, summary := l.dedup.check(suppressKey(e.Level, l.module, e.Message), l.module, e)
+		if summary != nil {
+			l.emitSummary(*summary)
+		}
+		if !ok {
+			l.suppressed.Add(1)
+			return false
+		}
+	}
+	if l.sampler != nil && !l.sampler.allow(suppressKey(e.Level, l.module, template), e.Time) {
+		l.sampled.Add(1)
+		return false
+	}
+	return true
+}
+
+func suppressKey(level Level, module, s string) string {
+	return strconv.Itoa(int(level)) + "\x00" + module + "\x00" + s
+}
+
+// =============================
+// 重复日志合并
+// =============================
+
+// deduper 窗口内相同级别、模块与内容的日志只输出第一条，窗口结束时补一条汇总
+type deduper struct {
+	window time.Duration
+
+	mu sync.Mutex
+	m  map[string]*dupState
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type dupState struct {
+	start    time.Time
+	module   string
+	repeated uint64
+	last     *Entry
+}
+
+// dupSummary 一条汇总日志，module 用于按模块统计
+type dupSummary struct {
+	e      *Entry
+	module string
+}
+
+// emitSummary 汇总与普通日志一样计入 Stats 与 WithMetricsHook，然后入队
+func (c *core) emitSummary(s dupSummary) {
+	c.countModule(s.e.Level, s.module)
+	c.enqueue(s.e)
+}
+
+func newDeduper(window time.Duration) *deduper {
+	return &deduper{
+		window: window,
+		m:      make(map[string]*dupState),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// check 返回 false 表示 e 在窗口内重复被合并；summary 非 nil 时为上一窗口的汇总，应在 e 之前输出
+func (d *deduper) check(key, module string, e *Entry) (ok bool, summary *dupSummary) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st := d.m[key]
+	if st == nil {
+		d.m[key] = &dupState{start: e.Time, module: module}
+		return true, nil
+	}
+	if e.Time.Sub(st.start) < d.window {
+		st.repeated++
+		st.last = e
+		return false, nil
+	}
+	summary = d.summary(st)
+	*st = dupState{start: e.Time, module: module}
+	return true, summary
+}
+
+func (d *deduper) summary(st *dupState) *dupSummary {
+	if st.repeated == 0 {
+		return nil
+	}
+	s := *st.last
+	s.Time = time.Now()
+	s.Message = fmt.Sprintf("%s (repeated %s times in %s)", s.Message, formatCount(st.repeated), d.window)
+	s.Fields = append(s.Fields[:len(s.Fields):len(s.Fields)], Field{Key: "repeated", Value: st.repeated})
+	return &dupSummary{e: &s, module: st.module}
+}
+
+// sweep 移除已结束的窗口并返回其汇总，all 为 true 时处理全部窗口（关闭时使用）
+func (d *deduper) sweep(now time.Time, all bool) []dupSummary {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []dupSummary
+	for key, st := range d.m {
+		if !all && now.Sub(st.start) < d.window {
+			continue
+		}
+		if s := d.summary(st); s != nil {
+			out = append(out, *s)
+		}
+		delete(d.m, key)
+	}
+	return out
+}
+
+// run 定期输出已结束窗口的汇总，重复日志停止后汇总也能及时写出
+func (d *deduper) run(emit func(dupSummary)) {
+	defer close(d.done)
+	ticker := time.NewTicker(max(d.window/2, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case now := <-ticker.C:
+			for _, s := range d.sweep(now, false) {
+				emit(s)
+			}
+		}
+	}
+}
+
+// close 停止后台任务并输出所有未结束窗口的汇总
+func (d *deduper) close(emit func(dupSummary)) {
+	close(d.stop)
+	<-d.done
+	for _, s := range d.sweep(time.Now(), true) {
+		emit(s)
+	}
+}
+
+// formatCount 千分位格式，如 4,312
+func formatCount(n uint64) string {
+	s := strconv.FormatUint(n, 10)
+	if len(s) <= 3 {
+		return s
+	}
+	b := make([]byte, 0, len(s)+len(s)/3)
+	pre := len(s) % 3
+	if pre > 0 {
+		b = append(b, s[:pre]...)
+	}
+	for i := pre; i < len(s); i += 3 {
+		if len(b) > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, s[i:i+3]...)
+	}
+	return string(b)
+}
+
+// =============================
+// 采样
+// =============================
+
+// sampler 按 key 计数，每个 tick 内前 first 条全部放行，之后每 thereafter 条放行 1 条
+type sampler struct {
+	tick       int64
+	first      uint64
+	thereafter uint64
+	counters   sync.Map // key -> *sampleCounter
+}
+
+type sampleCounter struct {
+	resetAt atomic.Int64
+	n       atomic.Uint64
+}
+
+func (s *sampler) allow(key string, t time.Time) bool {
+	v, ok := s.counters.Load(key)
+	if !ok {
+		v, _ = s.counters.LoadOrStore(key, new(sampleCounter))
+	}
+	n := v.(*sampleCounter).inc(t.UnixNano(), s.tick)
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+// inc 计数加一，超过 tick 后从 1 重新计数
+func (c *sampleCounter) inc(now, tick int64) uint64 {
+	resetAt := c.resetAt.Load()
+	if now < resetAt {
+		return c.n.Add(1)
+	}
+	if !c.resetAt.CompareAndSwap(resetAt, now+tick) {
+		return c.n.Add(1)
+	}
+	c.n.Store(1)
+	return 1
+}
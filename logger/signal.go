@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// SignalConfig HandleSignals 的配置
+type SignalConfig struct {
+	// CloseTimeout 收到 SIGTERM/SIGINT 后刷新或关闭 logger 的最长等待，默认 5s
+	CloseTimeout time.Duration
+	// Exit 为 true 时关闭 logger 后以 128+信号值 退出进程（SIGTERM 为 143，SIGINT 为 130），
+	// 适合没有自己信号处理的应用。默认只刷新 logger，不关闭也不退出：应用自己的信号处理同时收到信号，
+	// 优雅退出期间仍可写日志，最后由应用调用 Close
+	Exit bool
+}
+
+// HandleSignals 为 l 注册信号处理：
+//
+//	SIGHUP           重新打开日志文件（配合外部 logrotate）
+//	SIGTERM、SIGINT  写完队列中的日志；Exit 为 true 时随后关闭 logger 并退出进程
+//
+// 第一次收到 SIGTERM/SIGINT 后即取消对这两个信号的注册，之后的信号恢复 Go 的默认行为（终止进程），
+// 不会因 logger 吞掉信号而无法退出。返回的 stop 取消注册。用法：
+//
+//	stop := logger.HandleSignals(logger.Log, logger.SignalConfig{})
+//	defer stop()
+func HandleSignals(l LoggerInterface, cfg SignalConfig) (stop func()) {
+	if cfg.CloseTimeout <= 0 {
+		cfg.CloseTimeout = 5 * time.Second
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP, syscall.SIGTERM, os.Interrupt)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				if sig == syscall.SIGHUP {
+					if r, ok := l.(interface{ Reopen() }); ok {
+						r.Reopen()
+					}
+					continue
+				}
+				// 只保留 SIGHUP，再次收到 SIGTERM/SIGINT 时按默认行为终止进程
+				signal.Stop(ch)
+				signal.Notify(ch, syscall.SIGHUP)
+				ctx, cancel := context.WithTimeout(context.Background(), cfg.CloseTimeout)
+				if !cfg.Exit {
+					if err := flushContext(ctx, l); err != nil {
+						fmt.Fprintf(os.Stderr, "logger: %v\n", err)
+					}
+					cancel()
+					continue
+				}
+				if err := closeContext(ctx, l); err != nil {
+					fmt.Fprintf(os.Stderr, "logger: %v\n", err)
+				}
+				cancel()
+				code := 1
+				if s, ok := sig.(syscall.Signal); ok {
+					code = 128 + int(s)
+				}
+				os.Exit(code)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// closeContext 优先使用 CloseContext，其余实现在后台调用 Close 并等待至 ctx 结束
+func closeContext(ctx context.Context, l LoggerInterface) error {
+	if c, ok := l.(interface {
+		CloseContext(ctx context.Context) error
+	}); ok {
+		return c.CloseContext(ctx)
+	}
+	done := make(chan struct{})
+	go func() {
+		l.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("logger close: %w", ctx.Err())
+	}
+}
+
+// flushContext 在后台调用 Flush 并等待至 ctx 结束
+func flushContext(ctx context.Context, l LoggerInterface) error {
+	done := make(chan struct{})
+	go func() {
+		l.Flush()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("logger flush: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,308 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"utils/crpyto/aes"
+)
+
+// Sink 日志输出目的地
+type Sink interface {
+	// Write 写入一条日志，p 为按该 sink 的格式化器格式化后的内容
+	Write(e *Entry, p []byte) error
+	Close() error
+}
+
+// Flusher 由带缓冲的 sink 实现，logger 会定时以及在 Flush/Close 时调用
+type Flusher interface {
+	Flush() error
+}
+
+// Reopener 由写文件的 sink 实现，Logger.Reopen（如收到 SIGHUP）时关闭并重新打开文件，
+// 配合外部 logrotate 使用
+type Reopener interface {
+	Reopen() error
+}
+
+// sinkWorker 为每个 sink 启动独立的 goroutine，慢的 sink 不会阻塞其他 sink
+type sinkWorker struct {
+	sink      Sink
+	level     Level
+	formatter EntryFormatter // 为 nil 时使用 logger 的格式化器
+	core      *core
+	ch        chan *Entry
+	done      chan struct{}
+}
+
+func newSinkWorker(c *core, s Sink, level Level, formatter EntryFormatter) *sinkWorker {
+	w := &sinkWorker{
+		sink:      s,
+		level:     level,
+		formatter: formatter,
+		core:      c,
+		ch:        make(chan *Entry, cap(c.logChan)),
+		done:      make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *sinkWorker) run() {
+	defer close(w.done)
+	var tick <-chan time.Time
+	if _, ok := w.sink.(Flusher); ok && w.core.flushInterval > 0 {
+		t := time.NewTicker(w.core.flushInterval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case e, ok := <-w.ch:
+			if !ok {
+				w.flush()
+				return
+			}
+			if e.flush != nil {
+				w.flush()
+				if e.reopen {
+					w.reopen()
+				}
+				e.flush.Done()
+				continue
+			}
+			w.write(e)
+		case <-tick:
+			w.flush()
+		}
+	}
+}
+
+func (w *sinkWorker) write(e *Entry) {
+	f := w.formatter
+	if f == nil {
+		f = w.core.formatter.Load().(EntryFormatter)
+	}
+	if err := w.sink.Write(e, []byte(f(e))); err != nil {
+		w.core.writeErrors.Add(1)
+		fmt.Fprintf(os.Stderr, "logger write error: %v\n", err)
+	}
+}
+
+func (w *sinkWorker) flush() {
+	if fl, ok := w.sink.(Flusher); ok {
+		if err := fl.Flush(); err != nil {
+			w.core.writeErrors.Add(1)
+			fmt.Fprintf(os.Stderr, "logger flush error: %v\n", err)
+		}
+	}
+}
+
+func (w *sinkWorker) reopen() {
+	if r, ok := w.sink.(Reopener); ok {
+		if err := r.Reopen(); err != nil {
+			w.core.writeErrors.Add(1)
+			fmt.Fprintf(os.Stderr, "logger reopen error: %v\n", err)
+		}
+	}
+}
+
+// dispatch 投递到 sink，缓冲区满时按 logger 的溢出策略处理：
+// OverflowBlock 与 OverflowSpill 下阻塞等待，背压传导到主队列（Spill 策略由主队列落盘）
+func (w *sinkWorker) dispatch(e *Entry) {
+	if e.flush != nil {
+		w.ch <- e
+		return
+	}
+	if e.Level < w.level {
+		return
+	}
+	switch {
+	case e.Level >= FatalLevel, w.core.policy == OverflowBlock, w.core.policy == OverflowSpill:
+		w.ch <- e
+		return
+	}
+	select {
+	case w.ch <- e:
+		return
+	default:
+	}
+	if w.core.policy == OverflowDropOldest {
+		pushDropOldest(w.ch, e, &w.core.dropped)
+		return
+	}
+	w.core.dropped.Add(1)
+	fmt.Fprintf(os.Stderr, "logger sink full, drop log: %s\n", e.Message)
+}
+
+func (w *sinkWorker) close() {
+	close(w.ch)
+	<-w.done
+	if err := w.sink.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "logger close sink error: %v\n", err)
+	}
+}
+
+// =============================
+// 内置 sink
+// =============================
+
+// 文件 sink 默认的写缓冲大小
+const defaultWriteBufferSize = 256 * 1024
+
+// fileSink 滚动文件（lumberjack 或 RotatingFile），写入先进入缓冲区，写满或定时刷到文件
+type fileSink struct {
+	writer io.WriteCloser
+	buf    *bufio.Writer // 为 nil 时不缓冲
+	enc    *aes.Writer   // 非 nil 时加密写入，分块缓冲由 enc 负责
+}
+
+// NewFileSink 创建按大小滚动的文件 sink，参数含义同 NewLogger
+func NewFileSink(filename string, maxSize, maxBackups, maxAge int, compress bool) Sink {
+	return newFileSink(&lumberjack.Logger{
+		Filename:   filename,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	}, defaultWriteBufferSize)
+}
+
+// NewRotatingFileSink 创建支持按天/按小时滚动的文件 sink
+func NewRotatingFileSink(cfg RotateConfig) Sink {
+	return newFileSink(NewRotatingFile(cfg), defaultWriteBufferSize)
+}
+
+func newFileSink(w io.WriteCloser, bufSize int) *fileSink {
+	s := &fileSink{writer: w}
+	if bufSize > 0 {
+		s.buf = bufio.NewWriterSize(s.writer, bufSize)
+	}
+	return s
+}
+
+// newEncryptedFileSink 经 aes.Writer 分块认证加密后写入 w，每次 Flush 或写满 bufSize 写出一块
+func newEncryptedFileSink(w io.WriteCloser, bufSize int, key []byte) (*fileSink, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("encryption key must be 16, 24 or 32 bytes, got %d", len(key))
+	}
+	enc, err := aes.NewWriterSize(w, key, bufSize)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{writer: w, enc: enc}, nil
+}
+
+func (s *fileSink) Write(_ *Entry, p []byte) error {
+	var err error
+	switch {
+	case s.enc != nil:
+		_, err = s.enc.Write(p)
+	case s.buf != nil:
+		_, err = s.buf.Write(p)
+	default:
+		_, err = s.writer.Write(p)
+	}
+	return err
+}
+
+func (s *fileSink) Flush() error {
+	switch {
+	case s.enc != nil:
+		return s.enc.Flush()
+	case s.buf != nil:
+		return s.buf.Flush()
+	}
+	return nil
+}
+
+// Reopen 写出缓冲后关闭当前文件，下次写入时按原文件名重新打开
+func (s *fileSink) Reopen() error {
+	if err := s.Flush(); err != nil {
+		return err
+	}
+	switch w := s.writer.(type) {
+	case *RotatingFile:
+		return w.Rotate()
+	case *lumberjack.Logger:
+		// lumberjack 的 Close 只关闭文件，下次 Write 时重新打开
+		return w.Close()
+	}
+	return nil
+}
+
+func (s *fileSink) Close() error {
+	err := s.Flush()
+	if cerr := s.writer.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// writerSink 写入任意 io.Writer
+type writerSink struct {
+	w io.Writer
+}
+
+// NewWriterSink 创建写入 w 的 sink，Close 时若 w 实现了 io.Closer 则一并关闭
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+func (s *writerSink) Write(_ *Entry, p []byte) error {
+	_, err := s.w.Write(p)
+	return err
+}
+
+func (s *writerSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok && c != os.Stdout && c != os.Stderr {
+		return c.Close()
+	}
+	return nil
+}
+
+// consoleSink 输出到 stderr，可按级别着色
+type consoleSink struct {
+	w     io.Writer
+	color bool
+}
+
+// NewConsoleSink 创建输出到 stderr 的 sink，color 为 true 时按级别输出 ANSI 颜色
+func NewConsoleSink(color bool) Sink {
+	return &consoleSink{w: os.Stderr, color: color}
+}
+
+var levelColors = map[Level]string{
+	DebugLevel: "\x1b[90m",
+	InfoLevel:  "\x1b[36m",
+	WarnLevel:  "\x1b[33m",
+	ErrorLevel: "\x1b[31m",
+	FatalLevel: "\x1b[35m",
+}
+
+func (s *consoleSink) Write(e *Entry, p []byte) error {
+	if !s.color {
+		_, err := s.w.Write(p)
+		return err
+	}
+	color, ok := levelColors[e.Level]
+	if !ok {
+		_, err := s.w.Write(p)
+		return err
+	}
+	body := bytes.TrimSuffix(p, []byte("\n"))
+	_, err := fmt.Fprintf(s.w, "%s%s\x1b[0m\n", color, body)
+	return err
+}
+
+func (s *consoleSink) Close() error {
+	return nil
+}
@@ -0,0 +1,286 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// =============================
+// slog.Handler -> LoggerInterface
+// =============================
+
+// slogHandler 将 slog 的输出交给 LoggerInterface，
+// 对 *Logger 直接投递结构化记录，共用滚动文件、异步队列与 traceID 注入
+type slogHandler struct {
+	l      LoggerInterface
+	fields []Field
+	prefix string // WithGroup 产生的 key 前缀，如 "req."
+}
+
+// NewSlogHandler 返回以 l 为后端的 slog.Handler
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler(logger.Log)))
+func NewSlogHandler(l LoggerInterface) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return fromSlogLevel(level) >= h.l.GetLevel()
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make([]Field, 0, len(h.fields)+r.NumAttrs())
+	fields = append(fields, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, a)
+		return true
+	})
+	level := fromSlogLevel(r.Level)
+
+	l := h.l
+	if z, ok := l.(*lazyLogger); ok {
+		l = z.resolve()
+	}
+	lg, ok := l.(*Logger)
+	if !ok {
+		logAt(l, ctx, level, r.Message, fields)
+		return nil
+	}
+	if !lg.enabled(level) {
+		return nil
+	}
+	e := lg.newEntry(ctx, level, r.Message, fields)
+	if !r.Time.IsZero() {
+		e.Time = r.Time
+	}
+	if !lg.admit(e, r.Message) {
+		return nil
+	}
+	lg.count(level)
+	if lg.addCaller {
+		lg.fillCallerPC(e, r.PC)
+	}
+	lg.enqueue(e)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := make([]Field, 0, len(h.fields)+len(attrs))
+	fields = append(fields, h.fields...)
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.prefix, a)
+	}
+	return &slogHandler{l: h.l, fields: fields, prefix: h.prefix}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{l: h.l, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// appendAttr 将 slog.Attr 展开为 Field，分组以 "." 连接
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range attrs {
+			fields = appendAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
+
+// logAt 通过接口方法按级别写日志，FATAL 降级为 ERROR，避免 slog 调用导致进程退出
+func logAt(l LoggerInterface, ctx context.Context, level Level, msg string, fields []Field) {
+	switch {
+	case level <= DebugLevel:
+		l.Debug(ctx, "%s", msg, fields)
+	case level == InfoLevel:
+		l.Info(ctx, "%s", msg, fields)
+	case level == WarnLevel:
+		l.Warn(ctx, "%s", msg, fields)
+	default:
+		l.Error(ctx, "%s", msg, fields)
+	}
+}
+
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+func toSlogLevel(level Level) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 4
+	}
+}
+
+// =============================
+// LoggerInterface -> slog.Handler
+// =============================
+
+// slogLogger 以任意 slog.Handler 实现 LoggerInterface，便于逐步迁移
+type slogLogger struct {
+	h       slog.Handler
+	base    slog.Handler  // 不含 module 属性的 h，Named 由它派生，避免重复的 module 键
+	level   *atomic.Int32 // 与子 logger 共享
+	modules *moduleLevels
+
+	module      string
+	moduleLevel *moduleLevel
+}
+
+// NewSlogLogger 返回以 h 为后端的 LoggerInterface，级别默认 INFO。
+// 格式化由 h 负责，SetFormatter/SetEntryFormatter 无效；Flush/Close 不做任何事
+func NewSlogLogger(h slog.Handler) LoggerInterface {
+	l := &slogLogger{h: h, base: h, level: new(atomic.Int32), modules: new(moduleLevels)}
+	l.level.Store(int32(InfoLevel))
+	return l
+}
+
+func (l *slogLogger) log(ctx context.Context, level Level, format string, args []interface{}) {
+	if level < effectiveLevel(l.moduleLevel, l.level) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sl := toSlogLevel(level)
+	if !l.h.Enabled(ctx, sl) {
+		return
+	}
+	msg, fields := SplitArgs(format, args)
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // runtime.Callers、log、Info 等级别方法
+	r := slog.NewRecord(time.Now(), sl, msg, pcs[0])
+	traceID, spanID := traceFromContext(ctx)
+	if traceID != "" {
+		r.AddAttrs(slog.String("trace_id", traceID))
+	}
+	if spanID != "" {
+		r.AddAttrs(slog.String("span_id", spanID))
+	}
+	for _, f := range fields {
+		r.AddAttrs(fieldAttr(f))
+	}
+	_ = l.h.Handle(ctx, r)
+}
+
+func fieldAttr(f Field) slog.Attr {
+	if err, ok := f.Value.(error); ok {
+		return slog.String(f.Key, err.Error())
+	}
+	return slog.Any(f.Key, f.Value)
+}
+
+func (l *slogLogger) Debug(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, DebugLevel, format, args)
+}
+
+func (l *slogLogger) Info(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, InfoLevel, format, args)
+}
+
+func (l *slogLogger) Warn(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, WarnLevel, format, args)
+}
+
+func (l *slogLogger) Error(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, ErrorLevel, format, args)
+}
+
+func (l *slogLogger) Fatal(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, FatalLevel, format, args)
+	os.Exit(1)
+}
+
+func (l *slogLogger) With(fields ...Field) LoggerInterface {
+	if len(fields) == 0 {
+		return l
+	}
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		attrs = append(attrs, fieldAttr(f))
+	}
+	c := *l
+	c.h = l.h.WithAttrs(attrs)
+	c.base = l.base.WithAttrs(attrs)
+	return &c
+}
+
+func (l *slogLogger) Named(name string) LoggerInterface {
+	if l.module != "" {
+		name = l.module + "." + name
+	}
+	c := *l
+	c.h = l.base.WithAttrs([]slog.Attr{slog.String("module", name)})
+	c.module = name
+	c.moduleLevel = l.modules.get(name)
+	return &c
+}
+
+func (l *slogLogger) SetLevel(level Level) {
+	if l.moduleLevel != nil {
+		l.moduleLevel.level.Store(int32(level))
+		return
+	}
+	l.level.Store(int32(level))
+}
+
+func (l *slogLogger) GetLevel() Level {
+	return effectiveLevel(l.moduleLevel, l.level)
+}
+
+func (l *slogLogger) SetModuleLevel(name string, level Level) {
+	l.modules.set(name, level)
+}
+
+func (l *slogLogger) ReplaceModuleLevels(levels map[string]Level) {
+	l.modules.replace(levels)
+}
+
+func (l *slogLogger) Stats() Stats {
+	return Stats{}
+}
+
+func (l *slogLogger) SetFormatter(Formatter) {}
+
+func (l *slogLogger) SetEntryFormatter(EntryFormatter) {}
+
+func (l *slogLogger) Flush() {}
+
+func (l *slogLogger) Close() {}
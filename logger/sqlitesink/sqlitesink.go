@@ -0,0 +1,333 @@
+// Package sqlitesink 将日志写入本地 SQLite 表（通过 xsqlite.DB），适合边缘设备替代平面文件。
+//
+// 特性：
+//   - 批量插入，logger 定时 Flush 或攒满 BatchSize 条时写入一次事务
+//   - 按时间、级别、traceID 建索引
+//   - 按 Retention 定期清理旧日志
+//   - Query 按时间范围、级别、traceID、关键字查询，可用于简单的日志查看器
+//
+// 使用示例：
+//
+//	db, _ := xsqlite.Open(ctx, xsqlite.Config{DBPath: "data/logs.db"})
+//	sink, _ := sqlitesink.New(ctx, db, sqlitesink.Config{Retention: 7 * 24 * time.Hour})
+//	l := logger.NewLogger("", 0, 0, 0, false, nil, logger.WithSink(sink, logger.InfoLevel, nil))
+//	defer db.Close()
+//	defer l.Close() // 先关闭 logger（会 Flush 并关闭 sink），再关闭 db
+//
+//	records, _ := sink.Query(ctx, sqlitesink.Query{MinLevel: logger.WarnLevel, Contains: "timeout"})
+package sqlitesink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"utils/db/xsqlite"
+	"utils/logger"
+)
+
+type Config struct {
+	Table         string        // 表名，默认 logs
+	BatchSize     int           // 攒满多少条立即写入，默认 200
+	Retention     time.Duration // 日志保留时长，0 表示不清理
+	PruneInterval time.Duration // 清理间隔，默认 1h
+}
+
+// Sink 实现 logger.Sink 与 logger.Flusher
+type Sink struct {
+	db  *xsqlite.DB
+	cfg Config
+
+	mu      sync.Mutex
+	pending []row
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+type row struct {
+	ts      int64
+	level   logger.Level
+	traceID string
+	spanID  string
+	msg     string
+	fields  string
+	caller  string
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// New 建表并启动定期清理，db 的生命周期由调用方管理
+func New(ctx context.Context, db *xsqlite.DB, cfg Config) (*Sink, error) {
+	if db == nil {
+		return nil, errors.New("db required")
+	}
+	if cfg.Table == "" {
+		cfg.Table = "logs"
+	}
+	if !identRe.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("invalid table name %q", cfg.Table)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 200
+	}
+	if cfg.PruneInterval <= 0 {
+		cfg.PruneInterval = time.Hour
+	}
+
+	t := cfg.Table
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS ` + t + ` (
+			id       INTEGER PRIMARY KEY AUTOINCREMENT,
+			ts       INTEGER NOT NULL,
+			level    INTEGER NOT NULL,
+			trace_id TEXT    NOT NULL DEFAULT '',
+			span_id  TEXT    NOT NULL DEFAULT '',
+			msg      TEXT    NOT NULL,
+			fields   TEXT    NOT NULL DEFAULT '{}',
+			caller   TEXT    NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_` + t + `_ts ON ` + t + `(ts)`,
+		`CREATE INDEX IF NOT EXISTS idx_` + t + `_level_ts ON ` + t + `(level, ts)`,
+		`CREATE INDEX IF NOT EXISTS idx_` + t + `_trace_id ON ` + t + `(trace_id)`,
+	}
+	for _, m := range migrations {
+		if _, err := db.ExecSync(ctx, m); err != nil {
+			return nil, fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
+	s := &Sink{db: db, cfg: cfg, stop: make(chan struct{})}
+	if cfg.Retention > 0 {
+		s.wg.Add(1)
+		go s.pruneLoop()
+	}
+	return s, nil
+}
+
+// Write 将日志放入批次，攒满 BatchSize 条时立即写入
+func (s *Sink) Write(e *logger.Entry, _ []byte) error {
+	fields := "{}"
+	if len(e.Fields) > 0 {
+		m := make(map[string]interface{}, len(e.Fields))
+		for _, f := range e.Fields {
+			if err, ok := f.Value.(error); ok {
+				m[f.Key] = err.Error()
+			} else {
+				m[f.Key] = f.Value
+			}
+		}
+		if data, err := json.Marshal(m); err == nil {
+			fields = string(data)
+		}
+	}
+
+	s.mu.Lock()
+	s.pending = append(s.pending, row{
+		ts:      e.Time.UnixNano(),
+		level:   e.Level,
+		traceID: e.TraceID,
+		spanID:  e.SpanID,
+		msg:     e.Message,
+		fields:  fields,
+		caller:  e.Caller,
+	})
+	full := len(s.pending) >= s.cfg.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush 在一个事务中写入当前批次；失败时保留批次等待下次重试，最多积压 10 个批次
+func (s *Sink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := s.db.WithTx(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO `+s.cfg.Table+
+			`(ts, level, trace_id, span_id, msg, fields, caller) VALUES(?,?,?,?,?,?,?)`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, r := range s.pending {
+			if _, err := stmt.ExecContext(ctx, r.ts, int(r.level), r.traceID, r.spanID, r.msg, r.fields, r.caller); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if limit := s.cfg.BatchSize * 10; len(s.pending) > limit {
+			s.pending = append(s.pending[:0], s.pending[len(s.pending)-limit:]...)
+		}
+		return err
+	}
+	s.pending = s.pending[:0]
+	return nil
+}
+
+// Close 写入剩余日志并停止清理任务，不关闭 db
+func (s *Sink) Close() error {
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	s.wg.Wait()
+	return s.Flush()
+}
+
+func (s *Sink) pruneLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.cfg.PruneInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Prune(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "sqlitesink prune error: %v\n", err)
+		}
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune 删除超出保留时长的日志，返回删除的行数
+func (s *Sink) Prune(ctx context.Context) (int64, error) {
+	if s.cfg.Retention <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-s.cfg.Retention).UnixNano()
+	res, err := s.db.ExecSync(ctx, `DELETE FROM `+s.cfg.Table+` WHERE ts < ?`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// =============================
+// 查询
+// =============================
+
+// Query 查询条件，零值字段表示不限制
+type Query struct {
+	Since    time.Time
+	Until    time.Time
+	MinLevel logger.Level // 默认 DebugLevel，即全部级别
+	TraceID  string
+	Contains string // 在消息与字段中做子串匹配
+	Limit    int    // 默认 100
+	Offset   int
+	Asc      bool // 默认按时间倒序
+}
+
+// Record 查询结果
+type Record struct {
+	ID      int64
+	Time    time.Time
+	Level   logger.Level
+	TraceID string
+	SpanID  string
+	Message string
+	Fields  map[string]interface{}
+	Caller  string
+}
+
+// Query 在 sink 所写的表中查询
+func (s *Sink) Query(ctx context.Context, q Query) ([]Record, error) {
+	return QueryTable(ctx, s.db, s.cfg.Table, q)
+}
+
+// QueryTable 在指定表中查询，供只读的日志查看器使用
+func QueryTable(ctx context.Context, db *xsqlite.DB, table string, q Query) ([]Record, error) {
+	if !identRe.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+	var (
+		where []string
+		args  []interface{}
+	)
+	if !q.Since.IsZero() {
+		where = append(where, "ts >= ?")
+		args = append(args, q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "ts < ?")
+		args = append(args, q.Until.UnixNano())
+	}
+	if q.MinLevel > logger.DebugLevel {
+		where = append(where, "level >= ?")
+		args = append(args, int(q.MinLevel))
+	}
+	if q.TraceID != "" {
+		where = append(where, "trace_id = ?")
+		args = append(args, q.TraceID)
+	}
+	if q.Contains != "" {
+		pattern := "%" + escapeLike(q.Contains) + "%"
+		where = append(where, `(msg LIKE ? ESCAPE '\' OR fields LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	query := `SELECT id, ts, level, trace_id, span_id, msg, fields, caller FROM ` + table
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	if q.Asc {
+		query += " ORDER BY ts ASC, id ASC"
+	} else {
+		query += " ORDER BY ts DESC, id DESC"
+	}
+	query += " LIMIT ? OFFSET ?"
+	args = append(args, q.Limit, q.Offset)
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var (
+			r      Record
+			ts     int64
+			level  int
+			fields string
+		)
+		if err := rows.Scan(&r.ID, &ts, &level, &r.TraceID, &r.SpanID, &r.Message, &fields, &r.Caller); err != nil {
+			return nil, err
+		}
+		r.Time = time.Unix(0, ts)
+		r.Level = logger.Level(level)
+		if fields != "" && fields != "{}" {
+			_ = json.Unmarshal([]byte(fields), &r.Fields)
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
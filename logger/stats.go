@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// LevelCounts 按级别的计数，以 Level 为下标，如 counts[ErrorLevel]
+type LevelCounts [FatalLevel + 1]uint64
+
+// Stats logger 运行统计
+type Stats struct {
+	// Dropped 因队列写满被丢弃的日志条数（含各 sink 队列）
+	Dropped uint64
+	// Spilled 因队列写满写入磁盘缓冲的日志条数
+	Spilled uint64
+	// Suppressed 被 WithDedup 合并的重复日志条数
+	Suppressed uint64
+	// Sampled 被 WithSampling 丢弃的日志条数
+	Sampled uint64
+	// WriteErrors sink 写入、刷新或重新打开失败的次数
+	WriteErrors uint64
+
+	// Levels 各级别的日志条数，只统计通过级别过滤、合并与采样后实际入队的日志，含 WithDedup 的汇总
+	Levels LevelCounts
+	// Modules 各模块（Named 的名称）按级别的日志条数，未命名的日志不计入
+	Modules map[string]LevelCounts
+
+	// QueueLen 主队列当前长度，QueueCap 为其容量
+	QueueLen int
+	QueueCap int
+	// SinkQueueLen 各 sink 队列当前长度之和
+	SinkQueueLen int
+}
+
+// Stats 返回运行统计，可用于告警，Prometheus 格式见 WritePrometheus
+func (l *Logger) Stats() Stats {
+	s := Stats{
+		Dropped:     l.dropped.Load(),
+		Spilled:     l.spilled.Load(),
+		Suppressed:  l.suppressed.Load(),
+		Sampled:     l.sampled.Load(),
+		WriteErrors: l.writeErrors.Load(),
+		Levels:      l.levelCounts.load(),
+		Modules:     l.moduleCounts.load(),
+		QueueLen:    len(l.logChan),
+		QueueCap:    cap(l.logChan),
+	}
+	for _, w := range l.sinks {
+		s.SinkQueueLen += len(w.ch)
+	}
+	return s
+}
+
+// count 记录一条实际输出的日志，并调用 WithMetricsHook 注册的回调
+func (l *Logger) count(level Level) {
+	l.countLevel(level, l.module, l.counts)
+}
+
+// countModule 同 count，用于不经过 Logger 输出的日志（如 WithDedup 的汇总），模块计数按名称查找
+func (c *core) countModule(level Level, module string) {
+	var counts *levelCounters
+	if module != "" {
+		counts = c.moduleCounts.get(module)
+	}
+	c.countLevel(level, module, counts)
+}
+
+func (c *core) countLevel(level Level, module string, counts *levelCounters) {
+	if level < DebugLevel || level > FatalLevel {
+		return
+	}
+	c.levelCounts[level].Add(1)
+	if counts != nil {
+		counts[level].Add(1)
+	}
+	if c.metricsHook != nil {
+		c.metricsHook(level, module)
+	}
+}
+
+type levelCounters [FatalLevel + 1]atomic.Uint64
+
+func (c *levelCounters) load() LevelCounts {
+	var out LevelCounts
+	for i := range c {
+		out[i] = c[i].Load()
+	}
+	return out
+}
+
+// moduleCounters 按模块名保存的计数，Named 派生的 logger 持有其中的指针
+type moduleCounters struct {
+	mu sync.Mutex
+	m  map[string]*levelCounters
+}
+
+func (m *moduleCounters) get(name string) *levelCounters {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.m == nil {
+		m.m = make(map[string]*levelCounters)
+	}
+	c, ok := m.m[name]
+	if !ok {
+		c = new(levelCounters)
+		m.m[name] = c
+	}
+	return c
+}
+
+func (m *moduleCounters) load() map[string]LevelCounts {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.m) == 0 {
+		return nil
+	}
+	out := make(map[string]LevelCounts, len(m.m))
+	for name, c := range m.m {
+		out[name] = c.load()
+	}
+	return out
+}
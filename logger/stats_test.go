@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+// WithDedup 的汇总与普通日志一样计入 Stats 与 WithMetricsHook
+func TestStatsCountDedupSummary(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		hook = map[string]int{}
+	)
+	var out syncBuffer
+	l := NewLogger("", 0, 0, 0, false, textFormatter,
+		WithSink(NewWriterSink(&out), DebugLevel, nil),
+		WithDedup(time.Hour),
+		WithMetricsHook(func(level Level, module string) {
+			mu.Lock()
+			hook[level.String()+"/"+module]++
+			mu.Unlock()
+		}),
+	).(*Logger)
+
+	db := l.Named("db")
+	for i := 0; i < 3; i++ {
+		db.Warn(context.Background(), "connection refused")
+	}
+	l.Close() // 关闭时输出未结束窗口的汇总
+
+	if !strings.Contains(out.String(), "repeated 2 times") {
+		t.Fatalf("summary not written:\n%s", out.String())
+	}
+	s := l.Stats()
+	if s.Suppressed != 2 {
+		t.Errorf("Suppressed = %d, want 2", s.Suppressed)
+	}
+	if s.Levels[WarnLevel] != 2 {
+		t.Errorf("Levels[WARN] = %d, want 2 (first entry and summary)", s.Levels[WarnLevel])
+	}
+	if got := s.Modules["db"][WarnLevel]; got != 2 {
+		t.Errorf(`Modules["db"][WARN] = %d, want 2`, got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := hook[WarnLevel.String()+"/db"]; got != 2 {
+		t.Errorf("metrics hook calls = %v, want 2 for WARN/db", hook)
+	}
+}
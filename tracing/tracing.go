@@ -0,0 +1,181 @@
+// Package tracing 提供 W3C Trace Context（traceparent）的解析、生成与 context 传递。
+//
+// traceparent 格式：{version}-{trace-id}-{parent-id}-{trace-flags}
+//
+//	00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+//
+// 使用示例：
+//
+//	sc, err := tracing.Extract(r.Header)
+//	if err != nil {
+//	    sc = tracing.New() // 没有上游 trace 时新开一条
+//	} else {
+//	    sc = sc.Child() // 本服务作为新的 span
+//	}
+//	ctx := tracing.NewContext(r.Context(), sc)
+//	tracing.Inject(req.Header, sc) // 传给下游
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Header W3C 规定的 HTTP 头名称
+const Header = "traceparent"
+
+// FlagSampled trace-flags 中的采样位
+const FlagSampled byte = 0x01
+
+// TraceID 16 字节的 trace id
+type TraceID [16]byte
+
+// SpanID 8 字节的 span id
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsValid 全 0 的 trace id 无效
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsValid 全 0 的 span id 无效
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// NewTraceID 生成随机 trace id
+func NewTraceID() TraceID {
+	var t TraceID
+	for !t.IsValid() {
+		_, _ = rand.Read(t[:])
+	}
+	return t
+}
+
+// NewSpanID 生成随机 span id
+func NewSpanID() SpanID {
+	var s SpanID
+	for !s.IsValid() {
+		_, _ = rand.Read(s[:])
+	}
+	return s
+}
+
+// SpanContext 一次调用的链路信息
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+}
+
+// New 新开一条 trace，默认采样
+func New() SpanContext {
+	return SpanContext{TraceID: NewTraceID(), SpanID: NewSpanID(), Flags: FlagSampled}
+}
+
+// Child 返回同一 trace 下的新 span
+func (sc SpanContext) Child() SpanContext {
+	return SpanContext{TraceID: sc.TraceID, SpanID: NewSpanID(), Flags: sc.Flags}
+}
+
+// IsValid trace id 与 span id 均非全 0
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled 是否设置了采样位
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent 生成 version 00 的 traceparent 头
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+func (sc SpanContext) String() string {
+	return sc.Traceparent()
+}
+
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// Parse 解析 traceparent 头。
+// 按规范，version 为 ff 或 id 全 0 视为无效；高于 00 的版本允许在末尾附加字段
+func Parse(s string) (SpanContext, error) {
+	var sc SpanContext
+	s = strings.TrimSpace(s)
+	if len(s) < 55 {
+		return sc, ErrInvalidTraceparent
+	}
+	version, err := decodeHex(s[0:2])
+	if err != nil || version[0] == 0xff || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return sc, ErrInvalidTraceparent
+	}
+	if version[0] == 0 && len(s) != 55 {
+		return sc, ErrInvalidTraceparent
+	}
+	if len(s) > 55 && s[55] != '-' {
+		return sc, ErrInvalidTraceparent
+	}
+
+	tid, err := decodeHex(s[3:35])
+	if err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	sid, err := decodeHex(s[36:52])
+	if err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	flags, err := decodeHex(s[53:55])
+	if err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	copy(sc.TraceID[:], tid)
+	copy(sc.SpanID[:], sid)
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// decodeHex 只接受小写十六进制
+func decodeHex(s string) ([]byte, error) {
+	if strings.ToLower(s) != s {
+		return nil, ErrInvalidTraceparent
+	}
+	return hex.DecodeString(s)
+}
+
+// Extract 从 HTTP 头中读取 traceparent
+func Extract(h http.Header) (SpanContext, error) {
+	return Parse(h.Get(Header))
+}
+
+// Inject 将 sc 写入 HTTP 头
+func Inject(h http.Header, sc SpanContext) {
+	if sc.IsValid() {
+		h.Set(Header, sc.Traceparent())
+	}
+}
+
+type ctxKey struct{}
+
+// NewContext 将 sc 存入 context
+func NewContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, ctxKey{}, sc)
+}
+
+// FromContext 取出 context 中的 SpanContext
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	if ctx == nil {
+		return SpanContext{}, false
+	}
+	sc, ok := ctx.Value(ctxKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}